@@ -0,0 +1,83 @@
+package migrationhandler
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+)
+
+// migrationsFS is the file system migrations are read from, the archive of MigrationsArchive when it is set
+// or MigrationsFS, which is nil for the local file system
+func migrationsFS(dbConfig DBConfig) (fs.FS, error) {
+	if dbConfig.MigrationsArchive == "" {
+		return dbConfig.MigrationsFS, nil
+	}
+	fsys, err := openArchive(dbConfig.MigrationsArchive)
+	if err != nil {
+		return nil, fmt.Errorf("could not open migrations archive %s: %w", dbConfig.MigrationsArchive, err)
+	}
+	return fsys, nil
+}
+
+// openArchive reads a zip, tar or gzipped tar archive into memory as a fs.FS, tar archives are rewritten as
+// zip so both are read through zip.Reader
+func openArchive(archivePath string) (fs.FS, error) {
+	content, err := os.ReadFile(archivePath)
+	if err != nil {
+		return nil, err
+	}
+	lowerPath := strings.ToLower(archivePath)
+	switch {
+	case strings.HasSuffix(lowerPath, ".zip"):
+		return zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	case strings.HasSuffix(lowerPath, ".tar.gz"), strings.HasSuffix(lowerPath, ".tgz"):
+		reader, err := gzip.NewReader(bytes.NewReader(content))
+		if err != nil {
+			return nil, err
+		}
+		defer reader.Close()
+		return tarToZip(reader)
+	case strings.HasSuffix(lowerPath, ".tar"):
+		return tarToZip(bytes.NewReader(content))
+	}
+	return nil, errors.New("unsupported archive, use one of .zip, .tar, .tar.gz or .tgz")
+}
+
+// tarToZip copies the regular files of a tar archive into a zip archive kept in memory
+func tarToZip(r io.Reader) (fs.FS, error) {
+	var buffer bytes.Buffer
+	writer := zip.NewWriter(&buffer)
+	reader := tar.NewReader(r)
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		file, err := writer.Create(strings.TrimPrefix(header.Name, "./"))
+		if err != nil {
+			return nil, err
+		}
+		_, err = io.Copy(file, reader)
+		if err != nil {
+			return nil, err
+		}
+	}
+	err := writer.Close()
+	if err != nil {
+		return nil, err
+	}
+	return zip.NewReader(bytes.NewReader(buffer.Bytes()), int64(buffer.Len()))
+}
@@ -0,0 +1,140 @@
+package migrationhandler_test
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	migrationhandler "github.com/jvfrodrigues/gorm-migration-handler"
+)
+
+var archiveFiles = map[string]string{
+	"migrations/1_users_up.sql":    "CREATE TABLE archive_users (id integer);",
+	"migrations/1_users_down.sql":  "DROP TABLE archive_users;",
+	"migrations/2_orders_up.sql":   "CREATE TABLE archive_orders (id integer);",
+	"migrations/2_orders_down.sql": "DROP TABLE archive_orders;",
+}
+
+func writeZip(w io.Writer) error {
+	writer := zip.NewWriter(w)
+	for name, content := range archiveFiles {
+		file, err := writer.Create(name)
+		if err != nil {
+			return err
+		}
+		if _, err := file.Write([]byte(content)); err != nil {
+			return err
+		}
+	}
+	return writer.Close()
+}
+
+func writeTar(w io.Writer) error {
+	writer := tar.NewWriter(w)
+	for name, content := range archiveFiles {
+		err := writer.WriteHeader(&tar.Header{Name: "./" + name, Mode: 0o644, Size: int64(len(content)), Typeflag: tar.TypeReg})
+		if err != nil {
+			return err
+		}
+		if _, err := writer.Write([]byte(content)); err != nil {
+			return err
+		}
+	}
+	return writer.Close()
+}
+
+func writeTarGz(w io.Writer) error {
+	writer := gzip.NewWriter(w)
+	if err := writeTar(writer); err != nil {
+		return err
+	}
+	return writer.Close()
+}
+
+func TestRunMigrationsFromArchive(t *testing.T) {
+	tests := []struct {
+		name            string
+		fileName        string
+		write           func(w io.Writer) error
+		expectedError   error
+		expectedApplied []migrationhandler.AppliedMigration
+	}{
+		{
+			name:     "Test if migrations run from a zip archive",
+			fileName: "migrations.zip",
+			write:    writeZip,
+			expectedApplied: []migrationhandler.AppliedMigration{
+				{ID: "1", Name: "users"},
+				{ID: "2", Name: "orders"},
+			},
+		},
+		{
+			name:     "Test if migrations run from a tar archive",
+			fileName: "migrations.tar",
+			write:    writeTar,
+			expectedApplied: []migrationhandler.AppliedMigration{
+				{ID: "1", Name: "users"},
+				{ID: "2", Name: "orders"},
+			},
+		},
+		{
+			name:     "Test if migrations run from a gzipped tar archive",
+			fileName: "migrations.tar.gz",
+			write:    writeTarGz,
+			expectedApplied: []migrationhandler.AppliedMigration{
+				{ID: "1", Name: "users"},
+				{ID: "2", Name: "orders"},
+			},
+		},
+		{
+			name:          "Test if it errors on unsupported archives",
+			fileName:      "migrations.rar",
+			write:         writeZip,
+			expectedError: errors.New("could not open migrations archive %s: unsupported archive, use one of .zip, .tar, .tar.gz or .tgz"),
+		},
+	}
+	for i, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := tempDir(t)
+			defer func() {
+				_ = os.RemoveAll(dir)
+			}()
+			archivePath := filepath.Join(dir, tc.fileName)
+			file, err := os.Create(archivePath)
+			if err != nil {
+				t.Fatalf("test error: %v", err)
+			}
+			err = tc.write(file)
+			_ = file.Close()
+			if err != nil {
+				t.Fatalf("test error: %v", err)
+			}
+			applied, err := migrationhandler.RunMigrations(migrationhandler.DBConfig{
+				Dialector:            sqlite.Open(fmt.Sprintf("file:archive_test_%d?mode=memory&cache=shared", i)),
+				MigrationsFolderPath: "./migrations",
+				MigrationsArchive:    archivePath,
+			})
+			expectedError := tc.expectedError
+			if expectedError != nil {
+				expectedError = fmt.Errorf(expectedError.Error(), archivePath)
+			}
+			if err != nil || expectedError != nil {
+				if err == nil || expectedError == nil || err.Error() != expectedError.Error() {
+					t.Errorf("expected: %+v, got: %+v", expectedError, err)
+				}
+				return
+			}
+			if !reflect.DeepEqual(withoutDurations(applied), tc.expectedApplied) {
+				t.Errorf("expected: %+v, got: %+v", tc.expectedApplied, applied)
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package migrationhandler_test
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	migrationhandler "github.com/jvfrodrigues/gorm-migration-handler"
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// emptyDriver is a database/sql driver for a database without any tables, every query returns a single zero
+// so gorm finds nothing when it inspects the schema, letting the auto migration of any dialect run offline
+type emptyDriver struct{}
+
+// emptyBegins counts the transactions started on emptyDriver connections
+var emptyBegins atomic.Int64
+
+func (emptyDriver) Open(string) (driver.Conn, error) {
+	return emptyConn{}, nil
+}
+
+type emptyConn struct{}
+
+func (emptyConn) Prepare(string) (driver.Stmt, error) {
+	return emptyStmt{}, nil
+}
+
+func (emptyConn) Close() error {
+	return nil
+}
+
+func (emptyConn) Begin() (driver.Tx, error) {
+	emptyBegins.Add(1)
+	return emptyTx{}, nil
+}
+
+type emptyTx struct{}
+
+func (emptyTx) Commit() error {
+	return nil
+}
+
+func (emptyTx) Rollback() error {
+	return nil
+}
+
+type emptyStmt struct{}
+
+func (emptyStmt) Close() error {
+	return nil
+}
+
+func (emptyStmt) NumInput() int {
+	return -1
+}
+
+func (emptyStmt) Exec([]driver.Value) (driver.Result, error) {
+	return driver.RowsAffected(0), nil
+}
+
+func (emptyStmt) Query([]driver.Value) (driver.Rows, error) {
+	return &emptyRows{}, nil
+}
+
+type emptyRows struct {
+	done bool
+}
+
+func (*emptyRows) Columns() []string {
+	return []string{"count"}
+}
+
+func (*emptyRows) Close() error {
+	return nil
+}
+
+func (r *emptyRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	r.done = true
+	dest[0] = int64(0)
+	return nil
+}
+
+func init() {
+	sql.Register("empty", emptyDriver{})
+}
+
+type autoDiffProduct struct {
+	ID    uint
+	Code  string `gorm:"size:64;index"`
+	Price uint
+}
+
+func TestPreviewAutoMigrationDialects(t *testing.T) {
+	sqlDB, err := sql.Open("empty", "")
+	if err != nil {
+		t.Fatalf("test error: %v", err)
+	}
+	defer sqlDB.Close()
+	tests := []struct {
+		name              string
+		dialector         gorm.Dialector
+		expectedMigration string
+	}{
+		{
+			name:              "Test if the auto migration of mysql is kept",
+			dialector:         mysql.New(mysql.Config{Conn: sqlDB, SkipInitializeWithVersion: true}),
+			expectedMigration: "CREATE TABLE `auto_diff_products` (`id` bigint unsigned AUTO_INCREMENT,`code` varchar(64),`price` bigint unsigned,PRIMARY KEY (`id`),INDEX `idx_auto_diff_products_code` (`code`));\n",
+		},
+		{
+			name:      "Test if the auto migration of postgres is kept",
+			dialector: postgres.New(postgres.Config{Conn: sqlDB}),
+			expectedMigration: "CREATE TABLE \"auto_diff_products\" (\"id\" bigserial,\"code\" varchar(64),\"price\" bigint,PRIMARY KEY (\"id\"));\n" +
+				"CREATE INDEX IF NOT EXISTS \"idx_auto_diff_products_code\" ON \"auto_diff_products\" (\"code\");\n",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			db, err := gorm.Open(tc.dialector, &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+			if err != nil {
+				t.Fatalf("test error: %v", err)
+			}
+			migrationSQL, err := migrationhandler.PreviewAutoMigration(migrationhandler.DBConfig{
+				DB:     db,
+				Models: []interface{}{&autoDiffProduct{}},
+			})
+			if err != nil {
+				t.Fatalf("expected: %+v, got: %+v", nil, err)
+			}
+			if migrationSQL != tc.expectedMigration {
+				t.Errorf("expected: %+v, got: %+v", tc.expectedMigration, migrationSQL)
+			}
+			if strings.Contains(strings.ToUpper(migrationSQL), "SELECT") {
+				t.Errorf("expected schema queries to be left out, got: %+v", migrationSQL)
+			}
+		})
+	}
+}
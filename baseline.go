@@ -0,0 +1,57 @@
+package migrationhandler
+
+import "fmt"
+
+// Baseline gets DB info and gets all migrations from given folder to record every pending migration up to and
+// including throughID as applied without running their SQL, bringing a database that already has their schema
+// under management. On DryRun it only logs the migrations it would record
+func Baseline(dbConfig DBConfig, throughID string) error {
+	db, err := newDatabase(dbConfig)
+	if err != nil {
+		return fmt.Errorf("%w, can not baseline migrations: %w", ErrConnectionFailed, err)
+	}
+	migrations, err := getMigrations(dbConfig)
+	if err != nil {
+		return err
+	}
+	through := migrationIndex(migrations, throughID)
+	if through < 0 {
+		return fmt.Errorf("%w: %s", ErrMigrationNotFound, throughID)
+	}
+	options := managerOptions(dbConfig)
+	if !dbConfig.DryRun {
+		release, err := lockMigrations(db, dbConfig, options)
+		if err != nil {
+			return err
+		}
+		defer release()
+	}
+	applied, err := getAppliedMigrations(db, options)
+	if err != nil {
+		return err
+	}
+	pending := make([]migration, 0)
+	for _, migration := range migrations[:through+1] {
+		if _, ok := applied[migration.id]; ok {
+			continue
+		}
+		err = migration.loadUp()
+		if err != nil {
+			return err
+		}
+		pending = append(pending, migration)
+	}
+	logger := getLogger(dbConfig)
+	if dbConfig.DryRun {
+		for _, migration := range pending {
+			logger.Info("Migration '%s_%s' would be recorded as applied", migration.id, migration.name)
+		}
+		return nil
+	}
+	err = recordMigrations(db.Db, options, pending)
+	if err != nil {
+		return fmt.Errorf("could not record migrations: %w", err)
+	}
+	logger.Info("Baseline successful, %d recorded as applied", len(pending))
+	return nil
+}
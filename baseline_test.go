@@ -0,0 +1,93 @@
+package migrationhandler_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	migrationhandler "github.com/jvfrodrigues/gorm-migration-handler"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestBaseline(t *testing.T) {
+	tests := []struct {
+		name            string
+		throughID       string
+		dryRun          bool
+		expectedError   error
+		expectedApplied []string
+	}{
+		{
+			name:            "Test if migrations up to the given ID are recorded",
+			throughID:       "2",
+			expectedApplied: []string{"1", "2", "3"},
+		},
+		{
+			name:            "Test if nothing is recorded on a dry run",
+			throughID:       "2",
+			dryRun:          true,
+			expectedApplied: []string{"1"},
+		},
+		{
+			name:            "Test if it errors on non existing migration ID",
+			throughID:       "9",
+			expectedError:   errors.New("migration not found: 9"),
+			expectedApplied: []string{"1"},
+		},
+	}
+	for i, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dialector := sqlite.Open(fmt.Sprintf("file:baseline_test_%d?mode=memory&cache=shared", i))
+			db, err := gorm.Open(dialector, &gorm.Config{
+				Logger: logger.Default.LogMode(logger.Silent),
+			})
+			if err != nil {
+				t.Fatalf("test error: %v", err)
+			}
+			dir := tempDir(t)
+			defer func() {
+				_ = os.RemoveAll(dir)
+			}()
+			dbConfig := migrationhandler.DBConfig{
+				Dialector:            dialector,
+				MigrationsFolderPath: "./" + dir,
+			}
+			writeMigration(t, dir, "1_first_up.sql", "CREATE TABLE baseline_first (id integer);")
+			writeMigration(t, dir, "1_first_down.sql", "DROP TABLE baseline_first;")
+			err = migrationhandler.MigrateTo(dbConfig, "1")
+			if err != nil {
+				t.Fatalf("test error: %v", err)
+			}
+			// the schema of the second migration already exists, it would fail if it ran
+			if err := db.Exec("CREATE TABLE baseline_second (id integer)").Error; err != nil {
+				t.Fatalf("test error: %v", err)
+			}
+			writeMigration(t, dir, "2_second_up.sql", "CREATE TABLE baseline_second (id integer);")
+			writeMigration(t, dir, "2_second_down.sql", "DROP TABLE baseline_second;")
+			writeMigration(t, dir, "3_third_up.sql", "CREATE TABLE baseline_third (id integer);")
+			writeMigration(t, dir, "3_third_down.sql", "DROP TABLE baseline_third;")
+			dbConfig.DryRun = tc.dryRun
+			err = migrationhandler.Baseline(dbConfig, tc.throughID)
+			if err != nil || tc.expectedError != nil {
+				if err == nil || tc.expectedError == nil || err.Error() != tc.expectedError.Error() {
+					t.Errorf("expected: %+v, got: %+v", tc.expectedError, err)
+				}
+			}
+			dbConfig.DryRun = false
+			if !tc.dryRun && tc.expectedError == nil {
+				_, err = migrationhandler.RunMigrations(dbConfig)
+				if err != nil {
+					t.Fatalf("expected: %+v, got: %+v", nil, err)
+				}
+			}
+			applied := make([]string, 0)
+			db.Table("migrations").Order("id").Pluck("id", &applied)
+			if fmt.Sprint(applied) != fmt.Sprint(tc.expectedApplied) {
+				t.Errorf("expected applied: %+v, got: %+v", tc.expectedApplied, applied)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package migrationhandler
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+
+	"gorm.io/gorm"
+)
+
+// sqlCapturePool is a gorm.ConnPool that sends queries to the database but only records the statements
+// that would change it, so the auto migration SQL is collected without running it
+type sqlCapturePool struct {
+	gorm.ConnPool
+	dialector  gorm.Dialector
+	statements []string
+}
+
+func (p *sqlCapturePool) ExecContext(_ context.Context, query string, args ...interface{}) (sql.Result, error) {
+	p.statements = append(p.statements, p.dialector.Explain(query, args...))
+	return driver.RowsAffected(0), nil
+}
+
+func (p *sqlCapturePool) BeginTx(_ context.Context, _ *sql.TxOptions) (gorm.ConnPool, error) {
+	return &sqlCaptureTx{p}, nil
+}
+
+// sqlCaptureTx is the transaction started on a sqlCapturePool, as nothing is executed there is nothing to commit
+type sqlCaptureTx struct {
+	*sqlCapturePool
+}
+
+func (sqlCaptureTx) Commit() error {
+	return nil
+}
+
+func (sqlCaptureTx) Rollback() error {
+	return nil
+}
+
+// captureStatements runs fn on a session of db using a sqlCapturePool, returning the statements it would have run
+func captureStatements(db *gorm.DB, fn func(tx *gorm.DB) error) ([]string, error) {
+	capturePool := &sqlCapturePool{
+		ConnPool:  db.ConnPool,
+		dialector: db.Dialector,
+	}
+	// sessions share the statement of db unless given a context, which would leave db using the capture pool
+	tx := db.Session(&gorm.Session{Context: db.Statement.Context})
+	tx.Statement.ConnPool = capturePool
+	err := fn(tx)
+	return capturePool.statements, err
+}
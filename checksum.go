@@ -0,0 +1,76 @@
+package migrationhandler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+)
+
+// CheckMode sets how a problem found by a check is reported
+type CheckMode int
+
+const (
+	// CheckError stops the operation returning an error
+	CheckError CheckMode = iota
+	// CheckWarn logs a warning and carries on
+	CheckWarn
+	// CheckIgnore skips the check
+	CheckIgnore
+)
+
+// report returns err on CheckError, logs it on CheckWarn and drops it otherwise
+func (c CheckMode) report(logger Logger, err error) error {
+	switch c {
+	case CheckError:
+		return err
+	case CheckWarn:
+		logger.Warn("%v", err)
+	}
+	return nil
+}
+
+func checksum(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+// verifyChecksums compares the checksum recorded for every applied migration with its current up file,
+// migrations applied before checksums were recorded are skipped
+func (m *migrationManager) verifyChecksums(mode CheckMode) error {
+	if mode == CheckIgnore || !m.db.Db.Migrator().HasTable(recordsTableName(m.options)) {
+		return nil
+	}
+	applied, err := getAppliedMigrations(m.db, m.options)
+	if err != nil {
+		return err
+	}
+	var records []migrationRecord
+	err = m.db.Db.Table(recordsTableName(m.options)).Find(&records).Error
+	if err != nil {
+		return err
+	}
+	checksums := make(map[string]string)
+	for _, record := range records {
+		if _, ok := applied[record.ID]; ok {
+			checksums[record.ID] = record.Checksum
+		}
+	}
+	for _, migration := range m.migrations {
+		recorded, ok := checksums[migration.id]
+		if !ok || recorded == "" {
+			continue
+		}
+		err = migration.loadUp()
+		if err != nil {
+			return err
+		}
+		if recorded == migration.fileChecksum() {
+			continue
+		}
+		err = mode.report(m.logger, fmt.Errorf("checksum mismatch on migration %s_%s, its up file changed after it was applied", migration.id, migration.name))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
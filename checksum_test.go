@@ -0,0 +1,72 @@
+package migrationhandler_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	migrationhandler "github.com/jvfrodrigues/gorm-migration-handler"
+)
+
+func TestRunMigrationsChecksum(t *testing.T) {
+	tests := []struct {
+		name             string
+		checksumCheck    migrationhandler.CheckMode
+		expectedError    error
+		expectedWarnings int
+	}{
+		{
+			name:          "Test if it errors on modified applied migration",
+			checksumCheck: migrationhandler.CheckError,
+			expectedError: errors.New("checksum mismatch on migration 1_first, its up file changed after it was applied"),
+		},
+		{
+			name:             "Test if it warns on modified applied migration",
+			checksumCheck:    migrationhandler.CheckWarn,
+			expectedWarnings: 1,
+		},
+		{
+			name:          "Test if it ignores modified applied migration",
+			checksumCheck: migrationhandler.CheckIgnore,
+		},
+	}
+	for i, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := tempDir(t)
+			defer func() {
+				_ = os.RemoveAll(dir)
+			}()
+			logger := &testLogger{}
+			dbConfig := migrationhandler.DBConfig{
+				Dialector:            sqlite.Open(fmt.Sprintf("file:checksum_test_%v?mode=memory&cache=shared", i)),
+				MigrationsFolderPath: "./" + dir,
+				ChecksumCheck:        tc.checksumCheck,
+				Logger:               logger,
+			}
+			writeMigration(t, dir, "1_first_up.sql", "CREATE TABLE checksum_first (id integer);")
+			writeMigration(t, dir, "1_first_down.sql", "DROP TABLE checksum_first;")
+			_, err := migrationhandler.RunMigrations(dbConfig)
+			if err != nil {
+				t.Fatalf("test error: %v", err)
+			}
+			writeMigration(t, dir, "1_first_up.sql", "CREATE TABLE checksum_first (id integer, name text);")
+			_, err = migrationhandler.RunMigrations(dbConfig)
+			if err != nil || tc.expectedError != nil {
+				if err == nil || tc.expectedError == nil || err.Error() != tc.expectedError.Error() {
+					t.Errorf("expected: %+v, got: %+v", tc.expectedError, err)
+				}
+			}
+			warnings := 0
+			for _, message := range logger.messages {
+				if message[:4] == "WARN" {
+					warnings++
+				}
+			}
+			if warnings != tc.expectedWarnings {
+				t.Errorf("expected warnings: %+v, got: %+v", tc.expectedWarnings, warnings)
+			}
+		})
+	}
+}
@@ -0,0 +1,188 @@
+// Command migrationhandler creates, runs, rolls back and lists the SQL migrations of a folder
+//
+// Usage:
+//
+//	migrationhandler [flags] create <name>
+//	migrationhandler [flags] up
+//	migrationhandler [flags] down
+//	migrationhandler [flags] status
+//	migrationhandler [flags] repair
+//	migrationhandler [flags] baseline <id>
+//	migrationhandler [flags] fake <id>
+//	migrationhandler [flags] show <id>
+//	migrationhandler [flags] ping
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	migrationhandler "github.com/jvfrodrigues/gorm-migration-handler"
+	"github.com/jvfrodrigues/gorm-migration-handler/dialects"
+)
+
+func main() {
+	err := run(os.Args[1:], os.Stdout)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, out io.Writer) error {
+	flags := flag.NewFlagSet("migrationhandler", flag.ContinueOnError)
+	flags.SetOutput(out)
+	folder := flags.String("path", dialects.DefaultMigrationsFolderPath, "path of the migrations folder")
+	driver := flags.String("driver", "postgres", "database driver, one of postgres, mysql or sqlite")
+	dsn := flags.String("dsn", "", "data source name used to connect to the database")
+	table := flags.String("table", "", "name of the table keeping track of applied migrations")
+	allowDestructive := flags.Bool("allow-destructive", false, "write auto generated statements that can lose data, like DROP TABLE")
+	format := flags.String("format", "text", "output format, one of text or json")
+	fix := flags.Bool("fix", false, "make repair change the migrations table to match the folder instead of only reporting differences")
+	stdout := flags.Bool("stdout", false, "make create print the up and down files instead of writing them to the migrations folder")
+	retries := flags.Int("retries", 0, "times to retry connecting to the database while it is not ready, waiting longer after every attempt")
+	force := flags.Bool("force", false, "run or fake migrations out of order, warning about them instead of failing")
+	flags.Usage = func() {
+		fmt.Fprintln(out, "usage: migrationhandler [flags] create <name> | up | down | status | repair | baseline <id> | fake <id> | show <id> | ping")
+		flags.PrintDefaults()
+	}
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return errors.New("missing command")
+	}
+	if *format != "text" && *format != "json" {
+		return fmt.Errorf("unsupported format %s, use one of text or json", *format)
+	}
+	dialector, err := dialects.NewDialector(*driver, *dsn)
+	if err != nil {
+		return err
+	}
+	dbConfig := migrationhandler.DBConfig{
+		Dialector:            dialector,
+		MigrationsFolderPath: *folder,
+		TableName:            *table,
+		RepairRecords:        *fix,
+		ConnectRetries:       *retries,
+	}
+	if *allowDestructive {
+		dbConfig.DestructiveCheck = migrationhandler.CheckWarn
+	}
+	if *force {
+		dbConfig.OutOfOrderCheck = migrationhandler.CheckWarn
+	}
+	if *format == "json" {
+		// keeps the output a single JSON document
+		dbConfig.Logger = stderrLogger{}
+	}
+	if *stdout {
+		// keeps the output only the files, so it can be redirected to one
+		dbConfig.Output = out
+		dbConfig.Logger = stderrLogger{}
+	}
+	switch command := flags.Arg(0); command {
+	case "create":
+		if flags.NArg() != 2 {
+			return errors.New("usage: migrationhandler [flags] create <name>")
+		}
+		upPath, downPath, err := migrationhandler.CreateMigration(dbConfig, flags.Arg(1))
+		if err != nil || *stdout {
+			return err
+		}
+		if *format == "json" {
+			return json.NewEncoder(out).Encode(map[string]string{"up_path": upPath, "down_path": downPath})
+		}
+		fmt.Fprintf(out, "%s\n%s\n", upPath, downPath)
+		return nil
+	case "up":
+		summary, err := migrationhandler.RunMigrationsWithSummary(dbConfig)
+		if err != nil {
+			return err
+		}
+		if *format == "json" {
+			return json.NewEncoder(out).Encode(summary)
+		}
+		return nil
+	case "down":
+		rolledBack, err := migrationhandler.RollbackMigration(dbConfig)
+		if err != nil {
+			return err
+		}
+		if *format == "json" {
+			return json.NewEncoder(out).Encode(rolledBack)
+		}
+		return nil
+	case "status":
+		status, err := migrationhandler.MigrationStatus(dbConfig)
+		if err != nil {
+			return err
+		}
+		if *format == "json" {
+			return json.NewEncoder(out).Encode(status)
+		}
+		for _, info := range status {
+			state := "pending"
+			if info.Applied {
+				state = "applied"
+			}
+			if !info.AppliedAt.IsZero() {
+				state += " " + info.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Fprintf(out, "%s_%s\t%s\n", info.ID, info.Name, state)
+		}
+		return nil
+	case "repair":
+		return migrationhandler.Repair(dbConfig)
+	case "baseline":
+		if flags.NArg() != 2 {
+			return errors.New("usage: migrationhandler [flags] baseline <id>")
+		}
+		return migrationhandler.Baseline(dbConfig, flags.Arg(1))
+	case "fake":
+		if flags.NArg() != 2 {
+			return errors.New("usage: migrationhandler [flags] fake <id>")
+		}
+		return migrationhandler.FakeApply(dbConfig, flags.Arg(1))
+	case "show":
+		if flags.NArg() != 2 {
+			return errors.New("usage: migrationhandler [flags] show <id>")
+		}
+		up, down, err := migrationhandler.ShowMigration(dbConfig, flags.Arg(1))
+		if err != nil {
+			return err
+		}
+		if *format == "json" {
+			return json.NewEncoder(out).Encode(map[string]string{"up": up, "down": down})
+		}
+		fmt.Fprintf(out, "-- up\n%s\n-- down\n%s\n", strings.TrimRight(up, "\n"), strings.TrimRight(down, "\n"))
+		return nil
+	case "ping":
+		return migrationhandler.Ping(dbConfig)
+	default:
+		flags.Usage()
+		return fmt.Errorf("unknown command %s", command)
+	}
+}
+
+// stderrLogger prints the messages of the package to stderr, leaving stdout to the JSON output
+type stderrLogger struct{}
+
+func (stderrLogger) Info(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, format+"\n", args...)
+}
+
+func (stderrLogger) Warn(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, format+"\n", args...)
+}
+
+func (stderrLogger) Error(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, format+"\n", args...)
+}
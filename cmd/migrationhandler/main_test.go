@@ -0,0 +1,145 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	dir, err := os.MkdirTemp("./", "test_migrations")
+	if err != nil {
+		t.Fatalf("test error: %v", err)
+	}
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+	flags := []string{"-driver", "sqlite", "-dsn", "file:cli_test?mode=memory&cache=shared", "-path", dir}
+	err = os.WriteFile(dir+"/1_first_up.sql", []byte("CREATE TABLE cli_first (id integer);"), 0o644)
+	if err != nil {
+		t.Fatalf("test error: %v", err)
+	}
+	err = os.WriteFile(dir+"/1_first_down.sql", []byte("DROP TABLE cli_first;"), 0o644)
+	if err != nil {
+		t.Fatalf("test error: %v", err)
+	}
+	tests := []struct {
+		name           string
+		args           []string
+		expectedError  error
+		expectedOutput string
+	}{
+		{
+			name:           "Test if status lists pending migrations",
+			args:           append(flags, "status"),
+			expectedError:  nil,
+			expectedOutput: "1_first\tpending\n",
+		},
+		{
+			name:           "Test if up runs the migrations",
+			args:           append(flags, "up"),
+			expectedError:  nil,
+			expectedOutput: "",
+		},
+		{
+			name:           "Test if status lists applied migrations",
+			args:           append(flags, "status"),
+			expectedError:  nil,
+			expectedOutput: "1_first\tapplied ",
+		},
+		{
+			name:           "Test if status lists applied migrations as JSON",
+			args:           append(flags, "-format", "json", "status"),
+			expectedError:  nil,
+			expectedOutput: `[{"id":"1","name":"first","applied":true,"applied_at":"`,
+		},
+		{
+			name:           "Test if down rolls back the last migration",
+			args:           append(flags, "down"),
+			expectedError:  nil,
+			expectedOutput: "",
+		},
+		{
+			name:           "Test if up lists the applied migrations as JSON",
+			args:           append(flags, "-format", "json", "up"),
+			expectedError:  nil,
+			expectedOutput: `{"applied":[{"id":"1","name":"first","duration_ns":`,
+		},
+		{
+			name:           "Test if repair finds nothing to fix",
+			args:           append(flags, "repair"),
+			expectedError:  nil,
+			expectedOutput: "",
+		},
+		{
+			name:           "Test if create prints the files with stdout",
+			args:           append(flags, "-stdout", "create", "add_users"),
+			expectedError:  nil,
+			expectedOutput: "-- ",
+		},
+		{
+			name:           "Test if it errors on unknown formats",
+			args:           append(flags, "-format", "yaml", "status"),
+			expectedError:  errors.New("unsupported format yaml, use one of text or json"),
+			expectedOutput: "",
+		},
+		{
+			name:           "Test if create requires a name",
+			args:           append(flags, "create"),
+			expectedError:  errors.New("usage: migrationhandler [flags] create <name>"),
+			expectedOutput: "",
+		},
+		{
+			name:           "Test if baseline requires an ID",
+			args:           append(flags, "baseline"),
+			expectedError:  errors.New("usage: migrationhandler [flags] baseline <id>"),
+			expectedOutput: "",
+		},
+		{
+			name:           "Test if fake requires an ID",
+			args:           append(flags, "fake"),
+			expectedError:  errors.New("usage: migrationhandler [flags] fake <id>"),
+			expectedOutput: "",
+		},
+		{
+			name:           "Test if show prints the SQL of a migration",
+			args:           append(flags, "show", "1"),
+			expectedError:  nil,
+			expectedOutput: "-- up\nCREATE TABLE cli_first (id integer);\n-- down\nDROP TABLE cli_first;\n",
+		},
+		{
+			name:           "Test if ping connects to the database",
+			args:           append(flags, "ping"),
+			expectedError:  nil,
+			expectedOutput: "",
+		},
+		{
+			name:           "Test if it errors on unknown commands",
+			args:           append(flags, "sideways"),
+			expectedError:  errors.New("unknown command sideways"),
+			expectedOutput: "usage: migrationhandler",
+		},
+		{
+			name:           "Test if it errors on unknown drivers",
+			args:           []string{"-driver", "oracle", "up"},
+			expectedError:  errors.New("unsupported driver oracle, use one of postgres, mysql or sqlite"),
+			expectedOutput: "",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var out bytes.Buffer
+			err := run(tc.args, &out)
+			if err != nil || tc.expectedError != nil {
+				if err == nil || tc.expectedError == nil || err.Error() != tc.expectedError.Error() {
+					t.Errorf("expected: %+v, got: %+v", tc.expectedError, err)
+				}
+			}
+			if !strings.HasPrefix(out.String(), tc.expectedOutput) {
+				t.Errorf("expected output: %q, got: %q", tc.expectedOutput, out.String())
+			}
+		})
+	}
+}
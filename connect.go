@@ -0,0 +1,27 @@
+package migrationhandler
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DefaultConnectBackoff is the wait before the first retry of a failed connection when ConnectBackoff is not set
+const DefaultConnectBackoff = time.Second
+
+// openDialector opens the connection of Dialector, trying again up to ConnectRetries times when it fails. The wait
+// starts at ConnectBackoff and doubles after every attempt, returning the error of the last one
+func openDialector(dbConfig DBConfig, gormConfig *gorm.Config) (*gorm.DB, error) {
+	backoff := dbConfig.ConnectBackoff
+	if backoff <= 0 {
+		backoff = DefaultConnectBackoff
+	}
+	db, err := gorm.Open(dbConfig.Dialector, gormConfig)
+	for attempt := 1; err != nil && attempt <= dbConfig.ConnectRetries; attempt++ {
+		getLogger(dbConfig).Warn("Could not connect to the database, retrying in %s (%d/%d): %v", backoff, attempt, dbConfig.ConnectRetries, err)
+		time.Sleep(backoff)
+		backoff *= 2
+		db, err = gorm.Open(dbConfig.Dialector, gormConfig)
+	}
+	return db, err
+}
@@ -0,0 +1,103 @@
+package migrationhandler_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	migrationhandler "github.com/jvfrodrigues/gorm-migration-handler"
+	"gorm.io/gorm"
+)
+
+// startingDialector fails to connect until it was opened more than failures times, like a database still starting
+type startingDialector struct {
+	gorm.Dialector
+	failures int
+	opened   int
+}
+
+func (d *startingDialector) Initialize(db *gorm.DB) error {
+	d.opened++
+	if d.opened <= d.failures {
+		return errors.New("connection refused")
+	}
+	return d.Dialector.Initialize(db)
+}
+
+func TestRunMigrationsConnectRetries(t *testing.T) {
+	tests := []struct {
+		name             string
+		failures         int
+		connectRetries   int
+		expectedError    error
+		expectedMessages []string
+	}{
+		{
+			name:          "Test if it fails at once without retries",
+			failures:      1,
+			expectedError: errors.New("connection to database failed, can not run migrations: connection refused"),
+		},
+		{
+			name:           "Test if it connects once the database is up",
+			failures:       2,
+			connectRetries: 3,
+			expectedMessages: []string{
+				"WARN Could not connect to the database, retrying in 1ms (1/3): connection refused",
+				"WARN Could not connect to the database, retrying in 2ms (2/3): connection refused",
+			},
+		},
+		{
+			name:           "Test if it gives up after the last retry",
+			failures:       3,
+			connectRetries: 2,
+			expectedError:  errors.New("connection to database failed, can not run migrations: connection refused"),
+			expectedMessages: []string{
+				"WARN Could not connect to the database, retrying in 1ms (1/2): connection refused",
+				"WARN Could not connect to the database, retrying in 2ms (2/2): connection refused",
+			},
+		},
+	}
+	for i, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := tempDir(t)
+			defer func() {
+				_ = os.RemoveAll(dir)
+			}()
+			writeMigration(t, dir, "1_users_up.sql", "CREATE TABLE retry_users (id integer);")
+			writeMigration(t, dir, "1_users_down.sql", "DROP TABLE retry_users;")
+			logger := &testLogger{}
+			_, err := migrationhandler.RunMigrations(migrationhandler.DBConfig{
+				Dialector: &startingDialector{
+					Dialector: sqlite.Open(fmt.Sprintf("file:connect_test_%d?mode=memory&cache=shared", i)),
+					failures:  tc.failures,
+				},
+				MigrationsFolderPath: "./" + dir,
+				Logger:               logger,
+				ConnectRetries:       tc.connectRetries,
+				ConnectBackoff:       time.Millisecond,
+			})
+			if err != nil || tc.expectedError != nil {
+				if err == nil || tc.expectedError == nil || err.Error() != tc.expectedError.Error() {
+					t.Errorf("expected: %+v, got: %+v", tc.expectedError, err)
+				}
+			}
+			messages := make([]string, 0)
+			for _, message := range logger.messages {
+				if strings.HasPrefix(message, "WARN") {
+					messages = append(messages, message)
+				}
+			}
+			if len(tc.expectedMessages) == 0 {
+				tc.expectedMessages = []string{}
+			}
+			if !reflect.DeepEqual(messages, tc.expectedMessages) {
+				t.Errorf("expected: %+v, got: %+v", tc.expectedMessages, messages)
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package migrationhandler
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// pinnedConn is a connection of the pool used for every statement of the migrations, so the session settings
+// of ConnInitSQL apply to all of them. GetDBConn lets gorm still reach the pool, like for the advisory lock
+type pinnedConn struct {
+	*sql.Conn
+	pool *sql.DB
+}
+
+func (c pinnedConn) GetDBConn() (*sql.DB, error) {
+	return c.pool, nil
+}
+
+// initConnection runs ConnInitSQL on a connection taken from the pool of db, returning db set to only use it
+func initConnection(db *gorm.DB, initSQL []string) (*gorm.DB, error) {
+	ctx := context.Background()
+	pool, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	conn, err := pool.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, statement := range initSQL {
+		_, err = conn.ExecContext(ctx, statement)
+		if err != nil {
+			_ = conn.Close()
+			return nil, fmt.Errorf("connection init statement %q failed: %w", statement, err)
+		}
+	}
+	// a session with a context has a statement of its own, so the connection is not set on db
+	pinned := db.Session(&gorm.Session{Context: ctx})
+	pinned.Statement.ConnPool = pinnedConn{Conn: conn, pool: pool}
+	return pinned, nil
+}
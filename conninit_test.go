@@ -0,0 +1,59 @@
+package migrationhandler_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	migrationhandler "github.com/jvfrodrigues/gorm-migration-handler"
+)
+
+func TestRunMigrationsConnInitSQL(t *testing.T) {
+	tests := []struct {
+		name          string
+		connInitSQL   []string
+		expectedError error
+	}{
+		{
+			name:          "Test if migrations run without session settings",
+			connInitSQL:   nil,
+			expectedError: nil,
+		},
+		{
+			name:          "Test if every migration runs with the session settings",
+			connInitSQL:   []string{"PRAGMA foreign_keys = ON"},
+			expectedError: errors.New("constraint failed: FOREIGN KEY constraint failed (787)"),
+		},
+		{
+			name:          "Test if it errors on failing session settings",
+			connInitSQL:   []string{"SET search_path TO tenant"},
+			expectedError: errors.New(`connection to database failed, can not run migrations: connection init statement "SET search_path TO tenant" failed: SQL logic error: near "SET": syntax error (1)`),
+		},
+	}
+	for i, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := tempDir(t)
+			defer func() {
+				_ = os.RemoveAll(dir)
+			}()
+			writeMigration(t, dir, "1_users_up.sql", "CREATE TABLE conn_users (id integer PRIMARY KEY);")
+			writeMigration(t, dir, "1_users_down.sql", "DROP TABLE conn_users;")
+			writeMigration(t, dir, "2_orders_up.sql", "CREATE TABLE conn_orders (user_id integer REFERENCES conn_users (id));")
+			writeMigration(t, dir, "2_orders_down.sql", "DROP TABLE conn_orders;")
+			writeMigration(t, dir, "3_orphan_up.sql", "INSERT INTO conn_orders (user_id) VALUES (1);")
+			writeMigration(t, dir, "3_orphan_down.sql", "DELETE FROM conn_orders;")
+			_, err := migrationhandler.RunMigrations(migrationhandler.DBConfig{
+				Dialector:            sqlite.Open(fmt.Sprintf("file:conn_init_test_%d?mode=memory&cache=shared", i)),
+				MigrationsFolderPath: "./" + dir,
+				ConnInitSQL:          tc.connInitSQL,
+			})
+			if err != nil || tc.expectedError != nil {
+				if err == nil || tc.expectedError == nil || err.Error() != tc.expectedError.Error() {
+					t.Errorf("expected: %+v, got: %+v", tc.expectedError, err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package migrationhandler
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// DefaultDestructivePatterns match the auto generated statements that can lose data
+var DefaultDestructivePatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)\bDROP\s+TABLE\b`),
+	regexp.MustCompile(`(?i)\bDROP\s+COLUMN\b`),
+	regexp.MustCompile(`(?i)\bTRUNCATE\b`),
+}
+
+// checkDestructive looks for statements of the auto generated SQL matching the configured destructive
+// patterns, reporting them with the configured DestructiveCheck
+func checkDestructive(dbConfig DBConfig, migrationSQL string) error {
+	patterns := dbConfig.DestructivePatterns
+	if patterns == nil {
+		patterns = DefaultDestructivePatterns
+	}
+	offenders := make([]string, 0)
+	for _, statement := range strings.Split(migrationSQL, "\n") {
+		for _, pattern := range patterns {
+			if pattern.MatchString(statement) {
+				offenders = append(offenders, statement)
+				break
+			}
+		}
+	}
+	if len(offenders) == 0 {
+		return nil
+	}
+	err := fmt.Errorf("auto migration has destructive statements: %s", strings.Join(offenders, " "))
+	return dbConfig.DestructiveCheck.report(getLogger(dbConfig), err)
+}
@@ -0,0 +1,88 @@
+package migrationhandler_test
+
+import (
+	"errors"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	migrationhandler "github.com/jvfrodrigues/gorm-migration-handler"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type destructiveUser struct {
+	ID   uint
+	Name string
+}
+
+func TestCreateMigrationDestructive(t *testing.T) {
+	dialector := sqlite.Open("file:destructive_test?mode=memory&cache=shared")
+	db, err := gorm.Open(dialector, &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("test error: %v", err)
+	}
+	// changing the type of a column makes sqlite recreate the table, dropping the old one
+	err = db.Exec("CREATE TABLE destructive_users (id integer PRIMARY KEY, name integer)").Error
+	if err != nil {
+		t.Fatalf("test error: %v", err)
+	}
+	tests := []struct {
+		name          string
+		check         migrationhandler.CheckMode
+		patterns      []*regexp.Regexp
+		expectedError error
+		expectedFiles int
+	}{
+		{
+			name:          "Test if destructive statements are refused by default",
+			check:         migrationhandler.CheckError,
+			expectedError: errors.New("auto migration has destructive statements: DROP TABLE `destructive_users`;"),
+			expectedFiles: 0,
+		},
+		{
+			name:          "Test if destructive statements are written when allowed",
+			check:         migrationhandler.CheckWarn,
+			expectedError: nil,
+			expectedFiles: 2,
+		},
+		{
+			name:          "Test if the destructive patterns can be overridden",
+			check:         migrationhandler.CheckError,
+			patterns:      []*regexp.Regexp{regexp.MustCompile(`^TRUNCATE`)},
+			expectedError: nil,
+			expectedFiles: 2,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := tempDir(t)
+			defer func() {
+				_ = os.RemoveAll(dir)
+			}()
+			dbConfig := migrationhandler.DBConfig{
+				Dialector:            dialector,
+				Models:               []interface{}{&destructiveUser{}},
+				MigrationsFolderPath: "./" + dir,
+				DestructiveCheck:     tc.check,
+				DestructivePatterns:  tc.patterns,
+				Logger:               &testLogger{},
+			}
+			_, _, err := migrationhandler.CreateMigration(dbConfig, "test")
+			if err != nil || tc.expectedError != nil {
+				if err == nil || tc.expectedError == nil || !strings.HasPrefix(err.Error(), tc.expectedError.Error()) {
+					t.Errorf("expected: %+v, got: %+v", tc.expectedError, err)
+				}
+			}
+			dirFiles, err := os.ReadDir(dir)
+			if err != nil {
+				t.Fatalf("test error: %v", err)
+			}
+			if len(dirFiles) != tc.expectedFiles {
+				t.Errorf("expected: %+v files, got: %+v", tc.expectedFiles, len(dirFiles))
+			}
+		})
+	}
+}
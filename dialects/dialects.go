@@ -0,0 +1,26 @@
+// Package dialects builds the gorm dialectors of the drivers supported by the migration handler, so simple
+// setups do not need to import the gorm drivers themselves. It is kept apart from the migration handler so only
+// the programs using it depend on every driver
+package dialects
+
+import (
+	"github.com/glebarez/sqlite"
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// SQLite opens the SQLite database at path, which can also be a DSN like "file:test?mode=memory&cache=shared"
+func SQLite(path string) gorm.Dialector {
+	return sqlite.Open(path)
+}
+
+// Postgres opens the Postgres database of the dsn, like "host=localhost user=gorm dbname=gorm sslmode=disable"
+func Postgres(dsn string) gorm.Dialector {
+	return postgres.Open(dsn)
+}
+
+// MySQL opens the MySQL database of the dsn, like "gorm:gorm@tcp(127.0.0.1:3306)/gorm?parseTime=True"
+func MySQL(dsn string) gorm.Dialector {
+	return mysql.Open(dsn)
+}
@@ -0,0 +1,39 @@
+package dialects_test
+
+import (
+	"testing"
+
+	"github.com/jvfrodrigues/gorm-migration-handler/dialects"
+	"gorm.io/gorm"
+)
+
+func TestDialects(t *testing.T) {
+	tests := []struct {
+		name         string
+		dialector    gorm.Dialector
+		expectedName string
+	}{
+		{
+			name:         "Test if SQLite builds a sqlite dialector",
+			dialector:    dialects.SQLite("file:dialects_test?mode=memory&cache=shared"),
+			expectedName: "sqlite",
+		},
+		{
+			name:         "Test if Postgres builds a postgres dialector",
+			dialector:    dialects.Postgres("host=localhost user=gorm dbname=gorm sslmode=disable"),
+			expectedName: "postgres",
+		},
+		{
+			name:         "Test if MySQL builds a mysql dialector",
+			dialector:    dialects.MySQL("gorm:gorm@tcp(127.0.0.1:3306)/gorm"),
+			expectedName: "mysql",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.dialector.Name() != tc.expectedName {
+				t.Errorf("expected: %+v, got: %+v", tc.expectedName, tc.dialector.Name())
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package dialects
+
+import (
+	"fmt"
+	"os"
+
+	migrationhandler "github.com/jvfrodrigues/gorm-migration-handler"
+	"gorm.io/gorm"
+)
+
+// Environment variables read by DBConfigFromEnv
+const (
+	EnvDriver = "MIGRATION_DRIVER"
+	EnvDSN    = "MIGRATION_DSN"
+	EnvDir    = "MIGRATION_DIR"
+)
+
+// DefaultMigrationsFolderPath is the migrations folder used by DBConfigFromEnv when MIGRATION_DIR is not set
+const DefaultMigrationsFolderPath = "./migrations"
+
+// DBConfigFromEnv builds a DBConfig from the MIGRATION_DRIVER, MIGRATION_DSN and MIGRATION_DIR environment
+// variables. The driver is one of postgres, mysql or sqlite
+func DBConfigFromEnv() (migrationhandler.DBConfig, error) {
+	driver, ok := os.LookupEnv(EnvDriver)
+	if !ok || driver == "" {
+		return migrationhandler.DBConfig{}, fmt.Errorf("%s is not set", EnvDriver)
+	}
+	dialector, err := NewDialector(driver, os.Getenv(EnvDSN))
+	if err != nil {
+		return migrationhandler.DBConfig{}, err
+	}
+	folderPath := os.Getenv(EnvDir)
+	if folderPath == "" {
+		folderPath = DefaultMigrationsFolderPath
+	}
+	return migrationhandler.DBConfig{
+		Dialector:            dialector,
+		MigrationsFolderPath: folderPath,
+	}, nil
+}
+
+// NewDialector opens the gorm dialector of the given driver, one of postgres, mysql or sqlite, for the dsn
+func NewDialector(driver string, dsn string) (gorm.Dialector, error) {
+	switch driver {
+	case "postgres":
+		return Postgres(dsn), nil
+	case "mysql":
+		return MySQL(dsn), nil
+	case "sqlite":
+		return SQLite(dsn), nil
+	}
+	return nil, fmt.Errorf("unsupported driver %s, use one of postgres, mysql or sqlite", driver)
+}
@@ -0,0 +1,63 @@
+package dialects_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jvfrodrigues/gorm-migration-handler/dialects"
+)
+
+func TestDBConfigFromEnv(t *testing.T) {
+	tests := []struct {
+		name              string
+		env               map[string]string
+		expectedDialector string
+		expectedPath      string
+		expectedError     error
+	}{
+		{
+			name:              "Test if the config is built from the environment",
+			env:               map[string]string{"MIGRATION_DRIVER": "sqlite", "MIGRATION_DSN": "file::memory:", "MIGRATION_DIR": "./db/migrations"},
+			expectedDialector: "sqlite",
+			expectedPath:      "./db/migrations",
+			expectedError:     nil,
+		},
+		{
+			name:              "Test if the default folder is used when MIGRATION_DIR is not set",
+			env:               map[string]string{"MIGRATION_DRIVER": "postgres", "MIGRATION_DSN": "host=localhost", "MIGRATION_DIR": ""},
+			expectedDialector: "postgres",
+			expectedPath:      "./migrations",
+			expectedError:     nil,
+		},
+		{
+			name:          "Test if it errors when the driver is not set",
+			env:           map[string]string{"MIGRATION_DRIVER": "", "MIGRATION_DSN": "", "MIGRATION_DIR": ""},
+			expectedError: errors.New("MIGRATION_DRIVER is not set"),
+		},
+		{
+			name:          "Test if it errors on unsupported drivers",
+			env:           map[string]string{"MIGRATION_DRIVER": "oracle", "MIGRATION_DSN": "", "MIGRATION_DIR": ""},
+			expectedError: errors.New("unsupported driver oracle, use one of postgres, mysql or sqlite"),
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			for key, value := range tc.env {
+				t.Setenv(key, value)
+			}
+			dbConfig, err := dialects.DBConfigFromEnv()
+			if err != nil || tc.expectedError != nil {
+				if err == nil || tc.expectedError == nil || err.Error() != tc.expectedError.Error() {
+					t.Errorf("expected: %+v, got: %+v", tc.expectedError, err)
+				}
+				return
+			}
+			if dbConfig.Dialector.Name() != tc.expectedDialector {
+				t.Errorf("expected dialector: %+v, got: %+v", tc.expectedDialector, dbConfig.Dialector.Name())
+			}
+			if dbConfig.MigrationsFolderPath != tc.expectedPath {
+				t.Errorf("expected path: %+v, got: %+v", tc.expectedPath, dbConfig.MigrationsFolderPath)
+			}
+		})
+	}
+}
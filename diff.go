@@ -0,0 +1,250 @@
+package migrationhandler
+
+import (
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// RenamedTag is the struct tag CreateMigrationFromDiff reads to find renamed columns, a field tagged with
+// `migrationhandler:"renamed:old_name"` is written as a rename of the old_name column instead of a drop and add
+const RenamedTag = "migrationhandler"
+
+// parsedModel is a model with its parsed gorm schema
+type parsedModel struct {
+	model  interface{}
+	schema *schema.Schema
+}
+
+// CreateMigrationFromDiff requires the dbConfig, the name of the migration and the models before and after a
+// change, writing a migration with the statements turning the old models into the new ones and their inverse
+// as the down file, returning the paths of its up and down files. Tables are matched by name and created or
+// dropped, columns are matched by name or RenamedTag and added, dropped, renamed or have their type changed.
+//
+// Types are inferred from the Go fields with the data types of the dialect, like gorm's auto migration, so:
+//   - changes that only exist in the database, like columns edited by hand, are not seen
+//   - indexes, constraints and renamed tables are not compared, those have to be written by hand
+//   - postgres type changes only change the type, nullability and defaults have to be written by hand
+//   - sqlite can not change column types, a TODO comment is written in their place
+//
+// The database is only used for its dialect, the statements are not run and nothing is read from it
+func CreateMigrationFromDiff(databaseConfig DBConfig, migrationName string, oldModels []interface{}, newModels []interface{}) (string, string, error) {
+	err := validateMigrationName(databaseConfig, migrationName)
+	if err != nil {
+		return "", "", err
+	}
+	db, err := newDatabase(databaseConfig)
+	if err != nil {
+		return "", "", fmt.Errorf("%w, can not diff models: %w", ErrConnectionFailed, err)
+	}
+	oldTables, err := parseModels(db.Db, oldModels)
+	if err != nil {
+		return "", "", err
+	}
+	newTables, err := parseModels(db.Db, newModels)
+	if err != nil {
+		return "", "", err
+	}
+	diff := &modelDiff{db: db.Db}
+	for _, newTable := range newTables {
+		oldTable, found := findTable(oldTables, newTable.schema.Table)
+		if found {
+			diff.alterTable(oldTable, newTable)
+			continue
+		}
+		if err := diff.createTable(newTable); err != nil {
+			return "", "", err
+		}
+	}
+	for _, oldTable := range oldTables {
+		if _, found := findTable(newTables, oldTable.schema.Table); !found {
+			if err := diff.dropTable(oldTable); err != nil {
+				return "", "", err
+			}
+		}
+	}
+	migrationSQL := diff.upSQL()
+	err = checkDestructive(databaseConfig, migrationSQL)
+	if err != nil {
+		return "", "", err
+	}
+	if migrationSQL == "" {
+		if databaseConfig.SkipEmpty {
+			return "", "", ErrNoChanges
+		}
+		getLogger(databaseConfig).Info("No model changes found.")
+	}
+	newMigration := newMigration(databaseConfig, migrationName)
+	newMigration.migrationSQL = migrationSQL
+	newMigration.rollbackSQL = diff.downSQL()
+	return writeMigration(databaseConfig, newMigration)
+}
+
+// parseModels parses the gorm schema of every model, keeping their order
+func parseModels(db *gorm.DB, models []interface{}) ([]parsedModel, error) {
+	parsed := make([]parsedModel, 0, len(models))
+	for _, model := range models {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(model); err != nil {
+			return nil, fmt.Errorf("could not parse model %T: %w", model, err)
+		}
+		parsed = append(parsed, parsedModel{model: model, schema: stmt.Schema})
+	}
+	return parsed, nil
+}
+
+func findTable(tables []parsedModel, name string) (parsedModel, bool) {
+	for _, table := range tables {
+		if table.schema.Table == name {
+			return table, true
+		}
+	}
+	return parsedModel{}, false
+}
+
+// modelDiff collects the statements of the up file and their inverse, which are written to the down file in
+// reverse order
+type modelDiff struct {
+	db   *gorm.DB
+	up   []string
+	down []string
+}
+
+func (d *modelDiff) add(up []string, down []string) {
+	d.up = append(d.up, up...)
+	d.down = append(d.down, strings.Join(down, "\n"))
+}
+
+func (d *modelDiff) upSQL() string {
+	lines := ""
+	for _, statement := range d.up {
+		lines += statement + "\n"
+	}
+	return lines
+}
+
+func (d *modelDiff) downSQL() string {
+	lines := ""
+	for i := len(d.down) - 1; i >= 0; i-- {
+		lines += d.down[i] + "\n"
+	}
+	return lines
+}
+
+func (d *modelDiff) quote(name string) string {
+	return d.db.Statement.Quote(name)
+}
+
+// createTableSQL captures the statements gorm runs to create the table of a model and its indexes
+func (d *modelDiff) createTableSQL(table parsedModel) ([]string, error) {
+	statements, err := captureStatements(d.db, func(tx *gorm.DB) error {
+		return tx.Migrator().CreateTable(table.model)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not create table %s: %w", table.schema.Table, err)
+	}
+	for i := range statements {
+		statements[i] += ";"
+	}
+	return statements, nil
+}
+
+func (d *modelDiff) createTable(table parsedModel) error {
+	create, err := d.createTableSQL(table)
+	if err != nil {
+		return err
+	}
+	d.add(create, []string{fmt.Sprintf("DROP TABLE IF EXISTS %s;", d.quote(table.schema.Table))})
+	return nil
+}
+
+func (d *modelDiff) dropTable(table parsedModel) error {
+	create, err := d.createTableSQL(table)
+	if err != nil {
+		return err
+	}
+	d.add([]string{fmt.Sprintf("DROP TABLE IF EXISTS %s;", d.quote(table.schema.Table))}, create)
+	return nil
+}
+
+// alterTable compares the columns of a table in both model sets, new columns are matched to old ones by name
+// or by the column named in their RenamedTag
+func (d *modelDiff) alterTable(oldTable parsedModel, newTable parsedModel) {
+	table := d.quote(newTable.schema.Table)
+	matched := make(map[string]bool)
+	for _, dbName := range newTable.schema.DBNames {
+		newField := newTable.schema.FieldsByDBName[dbName]
+		if newField.IgnoreMigration {
+			continue
+		}
+		oldField, found := oldTable.schema.FieldsByDBName[dbName]
+		if !found {
+			if renamedFrom := renamedColumn(newField); renamedFrom != "" {
+				oldField, found = oldTable.schema.FieldsByDBName[renamedFrom]
+			}
+		}
+		if !found || matched[oldField.DBName] {
+			d.add(
+				[]string{fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s;", table, d.quote(dbName), d.columnType(newField))},
+				[]string{fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s;", table, d.quote(dbName))},
+			)
+			continue
+		}
+		matched[oldField.DBName] = true
+		// a renamed column changing its type is one change, so its down file changes the type back while the
+		// column still has its new name and renames it after
+		up, down := make([]string, 0), make([]string, 0)
+		if oldField.DBName != dbName {
+			up = append(up, fmt.Sprintf("ALTER TABLE %s RENAME COLUMN %s TO %s;", table, d.quote(oldField.DBName), d.quote(dbName)))
+			down = append(down, fmt.Sprintf("ALTER TABLE %s RENAME COLUMN %s TO %s;", table, d.quote(dbName), d.quote(oldField.DBName)))
+		}
+		if d.columnType(oldField) != d.columnType(newField) {
+			up = append(up, d.changeColumnType(table, dbName, newField, oldField))
+			down = append([]string{d.changeColumnType(table, dbName, oldField, newField)}, down...)
+		}
+		if len(up) > 0 {
+			d.add(up, down)
+		}
+	}
+	for _, dbName := range oldTable.schema.DBNames {
+		oldField := oldTable.schema.FieldsByDBName[dbName]
+		if matched[dbName] || oldField.IgnoreMigration {
+			continue
+		}
+		d.add(
+			[]string{fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s;", table, d.quote(dbName))},
+			[]string{fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s;", table, d.quote(dbName), d.columnType(oldField))},
+		)
+	}
+}
+
+// columnType is the column definition gorm uses for a field, its data type followed by nullability and default
+func (d *modelDiff) columnType(field *schema.Field) string {
+	return d.db.Migrator().FullDataTypeOf(field).SQL
+}
+
+// changeColumnType changes the column named dbName to the type of field, coming from the type of previous
+func (d *modelDiff) changeColumnType(table string, dbName string, field *schema.Field, previous *schema.Field) string {
+	column := d.quote(dbName)
+	switch d.db.Dialector.Name() {
+	case "mysql":
+		return fmt.Sprintf("ALTER TABLE %s MODIFY COLUMN %s %s;", table, column, d.columnType(field))
+	case "postgres":
+		dataType := d.db.Dialector.DataTypeOf(field)
+		return fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s TYPE %s USING %s::%s;", table, column, dataType, column, dataType)
+	}
+	return fmt.Sprintf("-- TODO: change column %s of table %s from %s to %s", column, table, d.columnType(previous), d.columnType(field))
+}
+
+// renamedColumn reads the previous column name of a field from its RenamedTag
+func renamedColumn(field *schema.Field) string {
+	for _, option := range strings.Split(field.Tag.Get(RenamedTag), ";") {
+		name, value, found := strings.Cut(option, ":")
+		if found && strings.EqualFold(strings.TrimSpace(name), "renamed") {
+			return strings.TrimSpace(value)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,254 @@
+package migrationhandler_test
+
+import (
+	"database/sql"
+	"os"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	migrationhandler "github.com/jvfrodrigues/gorm-migration-handler"
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type diffUserBefore struct {
+	ID   uint
+	Name string
+	Age  int
+}
+
+func (diffUserBefore) TableName() string {
+	return "diff_users"
+}
+
+type diffUserAfter struct {
+	ID       uint
+	FullName string `migrationhandler:"renamed:name"`
+	Age      string
+	Email    string
+}
+
+func (diffUserAfter) TableName() string {
+	return "diff_users"
+}
+
+type diffLegacy struct {
+	ID   uint
+	Code string
+}
+
+type diffPost struct {
+	ID    uint
+	Title string
+}
+
+func TestCreateMigrationFromDiff(t *testing.T) {
+	sqlDB, err := sql.Open("empty", "")
+	if err != nil {
+		t.Fatalf("test error: %v", err)
+	}
+	defer sqlDB.Close()
+	tests := []struct {
+		name         string
+		dialector    gorm.Dialector
+		expectedUp   string
+		expectedDown string
+		apply        bool
+	}{
+		{
+			name:      "Test if sqlite leaves type changes to be written by hand",
+			dialector: sqlite.Open(":memory:"),
+			expectedUp: "-- Write your SQL command here\n" +
+				"ALTER TABLE `diff_users` RENAME COLUMN `name` TO `full_name`;\n" +
+				"-- TODO: change column `age` of table `diff_users` from integer to text\n" +
+				"ALTER TABLE `diff_users` ADD COLUMN `email` text;\n" +
+				"CREATE TABLE `diff_posts` (`id` integer PRIMARY KEY AUTOINCREMENT,`title` text);\n" +
+				"DROP TABLE IF EXISTS `diff_legacies`;\n",
+			expectedDown: "-- Write your SQL command here\n" +
+				"CREATE TABLE `diff_legacies` (`id` integer PRIMARY KEY AUTOINCREMENT,`code` text);\n" +
+				"DROP TABLE IF EXISTS `diff_posts`;\n" +
+				"ALTER TABLE `diff_users` DROP COLUMN `email`;\n" +
+				"-- TODO: change column `age` of table `diff_users` from text to integer\n" +
+				"ALTER TABLE `diff_users` RENAME COLUMN `full_name` TO `name`;\n",
+			apply: true,
+		},
+		{
+			name:      "Test if mysql modifies changed columns",
+			dialector: mysql.New(mysql.Config{Conn: sqlDB, SkipInitializeWithVersion: true}),
+			expectedUp: "-- Write your SQL command here\n" +
+				"ALTER TABLE `diff_users` RENAME COLUMN `name` TO `full_name`;\n" +
+				"ALTER TABLE `diff_users` MODIFY COLUMN `age` longtext;\n" +
+				"ALTER TABLE `diff_users` ADD COLUMN `email` longtext;\n" +
+				"CREATE TABLE `diff_posts` (`id` bigint unsigned AUTO_INCREMENT,`title` longtext,PRIMARY KEY (`id`));\n" +
+				"DROP TABLE IF EXISTS `diff_legacies`;\n",
+			expectedDown: "-- Write your SQL command here\n" +
+				"CREATE TABLE `diff_legacies` (`id` bigint unsigned AUTO_INCREMENT,`code` longtext,PRIMARY KEY (`id`));\n" +
+				"DROP TABLE IF EXISTS `diff_posts`;\n" +
+				"ALTER TABLE `diff_users` DROP COLUMN `email`;\n" +
+				"ALTER TABLE `diff_users` MODIFY COLUMN `age` bigint;\n" +
+				"ALTER TABLE `diff_users` RENAME COLUMN `full_name` TO `name`;\n",
+		},
+		{
+			name:      "Test if postgres alters the type of changed columns",
+			dialector: postgres.New(postgres.Config{Conn: sqlDB}),
+			expectedUp: "-- Write your SQL command here\n" +
+				"ALTER TABLE \"diff_users\" RENAME COLUMN \"name\" TO \"full_name\";\n" +
+				"ALTER TABLE \"diff_users\" ALTER COLUMN \"age\" TYPE text USING \"age\"::text;\n" +
+				"ALTER TABLE \"diff_users\" ADD COLUMN \"email\" text;\n" +
+				"CREATE TABLE \"diff_posts\" (\"id\" bigserial,\"title\" text,PRIMARY KEY (\"id\"));\n" +
+				"DROP TABLE IF EXISTS \"diff_legacies\";\n",
+			expectedDown: "-- Write your SQL command here\n" +
+				"CREATE TABLE \"diff_legacies\" (\"id\" bigserial,\"code\" text,PRIMARY KEY (\"id\"));\n" +
+				"DROP TABLE IF EXISTS \"diff_posts\";\n" +
+				"ALTER TABLE \"diff_users\" DROP COLUMN \"email\";\n" +
+				"ALTER TABLE \"diff_users\" ALTER COLUMN \"age\" TYPE bigint USING \"age\"::bigint;\n" +
+				"ALTER TABLE \"diff_users\" RENAME COLUMN \"full_name\" TO \"name\";\n",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := tempDir(t)
+			defer func() {
+				_ = os.RemoveAll(dir)
+			}()
+			db, err := gorm.Open(tc.dialector, &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+			if err != nil {
+				t.Fatalf("test error: %v", err)
+			}
+			dbConfig := migrationhandler.DBConfig{
+				DB:                   db,
+				MigrationsFolderPath: "./" + dir,
+				DestructiveCheck:     migrationhandler.CheckIgnore,
+			}
+			upPath, downPath, err := migrationhandler.CreateMigrationFromDiff(dbConfig, "diff", []interface{}{&diffUserBefore{}, &diffLegacy{}}, []interface{}{&diffUserAfter{}, &diffPost{}})
+			if err != nil {
+				t.Fatalf("expected: %+v, got: %+v", nil, err)
+			}
+			up, err := os.ReadFile(upPath)
+			if err != nil {
+				t.Fatalf("test error: %v", err)
+			}
+			down, err := os.ReadFile(downPath)
+			if err != nil {
+				t.Fatalf("test error: %v", err)
+			}
+			if string(up) != tc.expectedUp {
+				t.Errorf("expected up: %+v, got: %+v", tc.expectedUp, string(up))
+			}
+			if string(down) != tc.expectedDown {
+				t.Errorf("expected down: %+v, got: %+v", tc.expectedDown, string(down))
+			}
+			if !tc.apply {
+				return
+			}
+			sqlDB, err := db.DB()
+			if err != nil {
+				t.Fatalf("test error: %v", err)
+			}
+			sqlDB.SetMaxOpenConns(1)
+			if err := db.AutoMigrate(&diffUserBefore{}, &diffLegacy{}); err != nil {
+				t.Fatalf("test error: %v", err)
+			}
+			if _, err := migrationhandler.RunMigrations(dbConfig); err != nil {
+				t.Fatalf("expected: %+v, got: %+v", nil, err)
+			}
+			if !db.Migrator().HasColumn("diff_users", "full_name") || !db.Migrator().HasTable("diff_posts") || db.Migrator().HasTable("diff_legacies") {
+				t.Errorf("expected the new models to be migrated")
+			}
+			if _, err := migrationhandler.RollbackMigration(dbConfig); err != nil {
+				t.Fatalf("expected: %+v, got: %+v", nil, err)
+			}
+			if !db.Migrator().HasColumn("diff_users", "name") || db.Migrator().HasTable("diff_posts") || !db.Migrator().HasTable("diff_legacies") {
+				t.Errorf("expected the old models to be restored")
+			}
+		})
+	}
+}
+
+type diffCustomerBefore struct {
+	ID   uint
+	Name string `gorm:"size:10"`
+}
+
+func (diffCustomerBefore) TableName() string {
+	return "diff_customers"
+}
+
+type diffCustomerAfter struct {
+	ID       uint
+	FullName string `gorm:"size:50" migrationhandler:"renamed:name"`
+}
+
+func (diffCustomerAfter) TableName() string {
+	return "diff_customers"
+}
+
+func TestCreateMigrationFromDiffRenameAndType(t *testing.T) {
+	sqlDB, err := sql.Open("empty", "")
+	if err != nil {
+		t.Fatalf("test error: %v", err)
+	}
+	defer sqlDB.Close()
+	tests := []struct {
+		name         string
+		dialector    gorm.Dialector
+		expectedUp   string
+		expectedDown string
+	}{
+		{
+			name:      "Test if mysql changes the type back before renaming the column back",
+			dialector: mysql.New(mysql.Config{Conn: sqlDB, SkipInitializeWithVersion: true}),
+			expectedUp: "-- Write your SQL command here\n" +
+				"ALTER TABLE `diff_customers` RENAME COLUMN `name` TO `full_name`;\n" +
+				"ALTER TABLE `diff_customers` MODIFY COLUMN `full_name` varchar(50);\n",
+			expectedDown: "-- Write your SQL command here\n" +
+				"ALTER TABLE `diff_customers` MODIFY COLUMN `full_name` varchar(10);\n" +
+				"ALTER TABLE `diff_customers` RENAME COLUMN `full_name` TO `name`;\n",
+		},
+		{
+			name:      "Test if postgres changes the type back before renaming the column back",
+			dialector: postgres.New(postgres.Config{Conn: sqlDB}),
+			expectedUp: "-- Write your SQL command here\n" +
+				"ALTER TABLE \"diff_customers\" RENAME COLUMN \"name\" TO \"full_name\";\n" +
+				"ALTER TABLE \"diff_customers\" ALTER COLUMN \"full_name\" TYPE varchar(50) USING \"full_name\"::varchar(50);\n",
+			expectedDown: "-- Write your SQL command here\n" +
+				"ALTER TABLE \"diff_customers\" ALTER COLUMN \"full_name\" TYPE varchar(10) USING \"full_name\"::varchar(10);\n" +
+				"ALTER TABLE \"diff_customers\" RENAME COLUMN \"full_name\" TO \"name\";\n",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := tempDir(t)
+			defer func() {
+				_ = os.RemoveAll(dir)
+			}()
+			db, err := gorm.Open(tc.dialector, &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+			if err != nil {
+				t.Fatalf("test error: %v", err)
+			}
+			upPath, downPath, err := migrationhandler.CreateMigrationFromDiff(migrationhandler.DBConfig{
+				DB:                   db,
+				MigrationsFolderPath: "./" + dir,
+			}, "rename", []interface{}{&diffCustomerBefore{}}, []interface{}{&diffCustomerAfter{}})
+			if err != nil {
+				t.Fatalf("expected: %+v, got: %+v", nil, err)
+			}
+			up, err := os.ReadFile(upPath)
+			if err != nil {
+				t.Fatalf("test error: %v", err)
+			}
+			down, err := os.ReadFile(downPath)
+			if err != nil {
+				t.Fatalf("test error: %v", err)
+			}
+			if string(up) != tc.expectedUp {
+				t.Errorf("expected up: %+v, got: %+v", tc.expectedUp, string(up))
+			}
+			if string(down) != tc.expectedDown {
+				t.Errorf("expected down: %+v, got: %+v", tc.expectedDown, string(down))
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package migrationhandler
+
+import "strings"
+
+const (
+	irreversibleDirective  = "irreversible"
+	noTransactionDirective = "no-transaction"
+	squashesDirective      = "squashes"
+	delimiterDirective     = "delimiter"
+	guardDirective         = "guard"
+)
+
+// hasDirective checks if the header of a migration file, the comment lines before its first statement,
+// has a line like "-- <directive>"
+func hasDirective(sql string, directive string) bool {
+	for _, line := range strings.Split(sql, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if !strings.HasPrefix(line, "--") {
+			return false
+		}
+		if strings.EqualFold(strings.TrimSpace(strings.TrimPrefix(line, "--")), directive) {
+			return true
+		}
+	}
+	return false
+}
+
+// directiveValue returns the value of a line like "-- <directive>: <value>" or "-- <directive> <value>"
+// on the header of a migration file
+func directiveValue(sql string, directive string) (string, bool) {
+	for _, line := range strings.Split(sql, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if !strings.HasPrefix(line, "--") {
+			return "", false
+		}
+		fields := strings.Fields(strings.TrimPrefix(line, "--"))
+		if len(fields) > 0 && strings.EqualFold(strings.TrimSuffix(fields[0], ":"), directive) {
+			return strings.Join(fields[1:], " "), true
+		}
+	}
+	return "", false
+}
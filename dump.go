@@ -0,0 +1,234 @@
+package migrationhandler
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// DumpSchema gets DB info and returns the CREATE statements of every table and index on the database, leaving out
+// the tables keeping track of migrations. When SchemaDumpPath is set the dump is written to it too.
+// Postgres has no way to show the statement that created a table, so its tables are rebuilt from their columns,
+// sequences and constraints
+func DumpSchema(dbConfig DBConfig) (string, error) {
+	db, err := newDatabase(dbConfig)
+	if err != nil {
+		return "", fmt.Errorf("%w, can not dump schema: %w", ErrConnectionFailed, err)
+	}
+	options := managerOptions(dbConfig)
+	skipped := map[string]bool{
+		options.TableName:            true,
+		recordsTableName(options):    true,
+		options.TableName + "_seeds": true,
+	}
+	var statements []string
+	switch db.Db.Dialector.Name() {
+	case "sqlite":
+		statements, err = dumpSQLite(db, skipped)
+	case "mysql":
+		statements, err = dumpMySQL(db, skipped)
+	case "postgres":
+		statements, err = dumpPostgres(db, skipped)
+	default:
+		err = fmt.Errorf("can not dump schema of %s databases", db.Db.Dialector.Name())
+	}
+	if err != nil {
+		return "", err
+	}
+	schema := ""
+	for _, statement := range statements {
+		schema += strings.TrimSuffix(strings.TrimSpace(statement), ";") + ";\n"
+	}
+	if dbConfig.SchemaDumpPath != "" {
+		err = os.WriteFile(dbConfig.SchemaDumpPath, []byte(schema), 0o644)
+		if err != nil {
+			return "", err
+		}
+	}
+	return schema, nil
+}
+
+func dumpSQLite(db *database, skipped map[string]bool) ([]string, error) {
+	var rows []struct {
+		TblName string
+		SQL     string
+	}
+	err := db.Db.Raw("SELECT tbl_name, sql FROM sqlite_master WHERE sql IS NOT NULL AND name NOT LIKE 'sqlite_%' ORDER BY type = 'index', tbl_name, name").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	statements := make([]string, 0, len(rows))
+	for _, row := range rows {
+		if !skipped[row.TblName] {
+			statements = append(statements, row.SQL)
+		}
+	}
+	return statements, nil
+}
+
+func dumpMySQL(db *database, skipped map[string]bool) ([]string, error) {
+	tables, err := dumpTables(db, skipped)
+	if err != nil {
+		return nil, err
+	}
+	statements := make([]string, 0, len(tables))
+	for _, table := range tables {
+		var row struct {
+			Table       string `gorm:"column:Table"`
+			CreateTable string `gorm:"column:Create Table"`
+		}
+		err = db.Db.Raw(fmt.Sprintf("SHOW CREATE TABLE `%s`", table)).Scan(&row).Error
+		if err != nil {
+			return nil, err
+		}
+		statements = append(statements, row.CreateTable)
+	}
+	return statements, nil
+}
+
+func dumpPostgres(db *database, skipped map[string]bool) ([]string, error) {
+	tables, err := dumpTables(db, skipped)
+	if err != nil {
+		return nil, err
+	}
+	// sequences come first so the defaults of the tables can use them and foreign keys last so the tables they
+	// point to exist, whatever the order of the tables
+	statements, owners, err := dumpPostgresSequences(db, skipped)
+	if err != nil {
+		return nil, err
+	}
+	constraints := make([]string, 0)
+	indexes := make([]string, 0)
+	foreignKeys := make([]string, 0)
+	for _, table := range tables {
+		var columns []struct {
+			ColumnName    string
+			DataType      string
+			NotNull       bool
+			ColumnDefault *string
+			Identity      string
+		}
+		err = db.Db.Raw("SELECT a.attname AS column_name, format_type(a.atttypid, a.atttypmod) AS data_type, a.attnotnull AS not_null, "+
+			"pg_get_expr(d.adbin, d.adrelid) AS column_default, a.attidentity::text AS identity FROM pg_attribute a "+
+			"LEFT JOIN pg_attrdef d ON d.adrelid = a.attrelid AND d.adnum = a.attnum "+
+			"WHERE a.attrelid = quote_ident(?)::regclass AND a.attnum > 0 AND NOT a.attisdropped ORDER BY a.attnum", table).Scan(&columns).Error
+		if err != nil {
+			return nil, err
+		}
+		definitions := make([]string, 0, len(columns))
+		for _, column := range columns {
+			definition := fmt.Sprintf("%q %s", column.ColumnName, column.DataType)
+			if column.NotNull {
+				definition += " NOT NULL"
+			}
+			if column.ColumnDefault != nil {
+				definition += " DEFAULT " + *column.ColumnDefault
+			}
+			switch column.Identity {
+			case "a":
+				definition += " GENERATED ALWAYS AS IDENTITY"
+			case "d":
+				definition += " GENERATED BY DEFAULT AS IDENTITY"
+			}
+			definitions = append(definitions, definition)
+		}
+		statements = append(statements, fmt.Sprintf("CREATE TABLE %q (\n  %s\n)", table, strings.Join(definitions, ",\n  ")))
+		var tableConstraints []struct {
+			Name       string
+			Type       string
+			Definition string
+		}
+		err = db.Db.Raw("SELECT conname AS name, contype::text AS type, pg_get_constraintdef(oid) AS definition FROM pg_constraint "+
+			"WHERE conrelid = quote_ident(?)::regclass AND contype IN ('p', 'u', 'c', 'x', 'f') ORDER BY conname", table).
+			Scan(&tableConstraints).Error
+		if err != nil {
+			return nil, err
+		}
+		for _, constraint := range tableConstraints {
+			statement := fmt.Sprintf("ALTER TABLE %q ADD CONSTRAINT %q %s", table, constraint.Name, constraint.Definition)
+			if constraint.Type == "f" {
+				foreignKeys = append(foreignKeys, statement)
+			} else {
+				constraints = append(constraints, statement)
+			}
+		}
+		// the indexes of primary keys, unique and exclusion constraints are created by their constraints
+		var tableIndexes []string
+		err = db.Db.Raw("SELECT indexdef FROM pg_indexes WHERE schemaname = CURRENT_SCHEMA() AND tablename = ? AND indexname NOT IN "+
+			"(SELECT conname FROM pg_constraint WHERE conrelid = quote_ident(?)::regclass AND contype IN ('p', 'u', 'x')) ORDER BY indexname", table, table).
+			Scan(&tableIndexes).Error
+		if err != nil {
+			return nil, err
+		}
+		indexes = append(indexes, tableIndexes...)
+	}
+	statements = append(statements, constraints...)
+	statements = append(statements, indexes...)
+	statements = append(statements, owners...)
+	return append(statements, foreignKeys...), nil
+}
+
+// dumpPostgresSequences returns the CREATE statements of the sequences of the database and the statements tying
+// them to the columns owning them, which can only run once the tables exist. Sequences of identity columns are
+// left out as the columns create them, and so are the ones owned by skipped tables
+func dumpPostgresSequences(db *database, skipped map[string]bool) ([]string, []string, error) {
+	var sequences []struct {
+		SequenceName string
+		DataType     string
+		StartValue   int64
+		IncrementBy  int64
+		MinValue     int64
+		MaxValue     int64
+		Cycle        bool
+		OwnerTable   *string
+		OwnerColumn  *string
+		Dependency   *string
+	}
+	err := db.Db.Raw("SELECT s.sequencename AS sequence_name, s.data_type::text AS data_type, s.start_value, s.increment_by, s.min_value, " +
+		"s.max_value, s.cycle, t.relname AS owner_table, a.attname AS owner_column, d.deptype::text AS dependency FROM pg_sequences s " +
+		"LEFT JOIN pg_depend d ON d.classid = 'pg_class'::regclass AND d.refclassid = 'pg_class'::regclass AND d.deptype IN ('a', 'i') " +
+		"AND d.objid = (quote_ident(s.schemaname) || '.' || quote_ident(s.sequencename))::regclass " +
+		"LEFT JOIN pg_class t ON t.oid = d.refobjid LEFT JOIN pg_attribute a ON a.attrelid = d.refobjid AND a.attnum = d.refobjsubid " +
+		"WHERE s.schemaname = CURRENT_SCHEMA() ORDER BY s.sequencename").Scan(&sequences).Error
+	if err != nil {
+		return nil, nil, err
+	}
+	statements := make([]string, 0, len(sequences))
+	owners := make([]string, 0)
+	for _, sequence := range sequences {
+		if sequence.Dependency != nil && *sequence.Dependency == "i" {
+			continue
+		}
+		if sequence.OwnerTable != nil && skipped[*sequence.OwnerTable] {
+			continue
+		}
+		statement := fmt.Sprintf("CREATE SEQUENCE %q AS %s INCREMENT BY %d MINVALUE %d MAXVALUE %d START WITH %d",
+			sequence.SequenceName, sequence.DataType, sequence.IncrementBy, sequence.MinValue, sequence.MaxValue, sequence.StartValue)
+		if sequence.Cycle {
+			statement += " CYCLE"
+		}
+		statements = append(statements, statement)
+		if sequence.OwnerTable != nil && sequence.OwnerColumn != nil {
+			owners = append(owners, fmt.Sprintf("ALTER SEQUENCE %q OWNED BY %q.%q", sequence.SequenceName, *sequence.OwnerTable, *sequence.OwnerColumn))
+		}
+	}
+	return statements, owners, nil
+}
+
+// dumpTables lists the tables of the database to dump sorted by name
+func dumpTables(db *database, skipped map[string]bool) ([]string, error) {
+	tables, err := db.Db.Migrator().GetTables()
+	if err != nil {
+		return nil, err
+	}
+	dumped := make([]string, 0, len(tables))
+	for _, table := range tables {
+		if !skipped[table] {
+			dumped = append(dumped, table)
+		}
+	}
+	sort.Strings(dumped)
+	return dumped, nil
+}
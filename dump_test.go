@@ -0,0 +1,212 @@
+package migrationhandler_test
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	migrationhandler "github.com/jvfrodrigues/gorm-migration-handler"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestDumpSchema(t *testing.T) {
+	dir := tempDir(t)
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+	dbConfig := migrationhandler.DBConfig{
+		Dialector:            sqlite.Open("file:dump_test?mode=memory&cache=shared"),
+		MigrationsFolderPath: "./" + dir,
+		SchemaDumpPath:       filepath.Join(dir, "schema.sql"),
+	}
+	writeMigration(t, dir, "1_users_up.sql", "CREATE TABLE users (id integer primary key, name text);\nCREATE INDEX idx_users_name ON users (name);")
+	writeMigration(t, dir, "1_users_down.sql", "DROP TABLE users;")
+	writeMigration(t, dir, "2_orders_up.sql", "CREATE TABLE orders (id integer primary key, user_id integer);")
+	writeMigration(t, dir, "2_orders_down.sql", "DROP TABLE orders;")
+	_, err := migrationhandler.RunMigrations(dbConfig)
+	if err != nil {
+		t.Fatalf("test error: %v", err)
+	}
+	schema, err := migrationhandler.DumpSchema(dbConfig)
+	if err != nil {
+		t.Fatalf("expected: %+v, got: %+v", nil, err)
+	}
+	expected := "CREATE TABLE orders (id integer primary key, user_id integer);\n" +
+		"CREATE TABLE users (id integer primary key, name text);\n" +
+		"CREATE INDEX idx_users_name ON users (name);\n"
+	if schema != expected {
+		t.Errorf("expected: %+v, got: %+v", expected, schema)
+	}
+	written, err := os.ReadFile(dbConfig.SchemaDumpPath)
+	if err != nil {
+		t.Fatalf("expected schema to be written, got: %+v", err)
+	}
+	if string(written) != expected {
+		t.Errorf("expected written schema: %+v, got: %+v", expected, string(written))
+	}
+}
+
+// catalogDriver is a database/sql driver answering the queries of DumpSchema on postgres with the rows of the
+// first entry of catalogRows whose key is in the query, so the dump runs offline
+type catalogDriver struct{}
+
+// catalogRows holds the columns and rows of the catalog queries by the table they are about, keyed by a part of
+// the query. The queries joining other catalogs come first
+var catalogRows = []struct {
+	key  string
+	rows func(table string) ([]string, [][]driver.Value)
+}{
+	{"information_schema.tables", func(string) ([]string, [][]driver.Value) {
+		return []string{"table_name"}, [][]driver.Value{{"users"}, {"orders"}, {"migrations"}}
+	}},
+	{"pg_sequences", func(string) ([]string, [][]driver.Value) {
+		columns := []string{"sequence_name", "data_type", "start_value", "increment_by", "min_value", "max_value", "cycle", "owner_table", "owner_column", "dependency"}
+		return columns, [][]driver.Value{
+			{"migrations_id_seq", "integer", int64(1), int64(1), int64(1), int64(2147483647), false, "migrations", "id", "a"},
+			{"orders_id_seq", "bigint", int64(1), int64(1), int64(1), int64(9223372036854775807), false, nil, nil, nil},
+			{"users_id_seq", "bigint", int64(1), int64(1), int64(1), int64(9223372036854775807), false, "users", "id", "a"},
+			{"users_tag_seq", "integer", int64(1), int64(1), int64(1), int64(2147483647), false, "users", "tag", "i"},
+		}
+	}},
+	{"pg_indexes", func(table string) ([]string, [][]driver.Value) {
+		if table == "users" {
+			return []string{"indexdef"}, [][]driver.Value{{"CREATE INDEX idx_users_name ON public.users USING btree (name)"}}
+		}
+		return []string{"indexdef"}, nil
+	}},
+	{"pg_attribute", func(table string) ([]string, [][]driver.Value) {
+		columns := []string{"column_name", "data_type", "not_null", "column_default", "identity"}
+		if table == "users" {
+			return columns, [][]driver.Value{
+				{"id", "bigint", true, "nextval('users_id_seq'::regclass)", ""},
+				{"name", "character varying(64)", false, nil, ""},
+				{"tag", "integer", true, nil, "a"},
+			}
+		}
+		return columns, [][]driver.Value{
+			{"id", "bigint", true, "nextval('orders_id_seq'::regclass)", ""},
+			{"user_id", "bigint", false, nil, ""},
+		}
+	}},
+	{"pg_constraint", func(table string) ([]string, [][]driver.Value) {
+		columns := []string{"name", "type", "definition"}
+		if table == "users" {
+			return columns, [][]driver.Value{{"users_pkey", "p", "PRIMARY KEY (id)"}}
+		}
+		return columns, [][]driver.Value{
+			{"fk_orders_user", "f", "FOREIGN KEY (user_id) REFERENCES users(id)"},
+			{"orders_pkey", "p", "PRIMARY KEY (id)"},
+		}
+	}},
+}
+
+func (catalogDriver) Open(string) (driver.Conn, error) {
+	return catalogConn{}, nil
+}
+
+type catalogConn struct{}
+
+func (catalogConn) Prepare(query string) (driver.Stmt, error) {
+	return catalogStmt{query}, nil
+}
+
+func (catalogConn) Close() error {
+	return nil
+}
+
+func (catalogConn) Begin() (driver.Tx, error) {
+	return emptyTx{}, nil
+}
+
+type catalogStmt struct {
+	query string
+}
+
+func (catalogStmt) Close() error {
+	return nil
+}
+
+func (catalogStmt) NumInput() int {
+	return -1
+}
+
+func (catalogStmt) Exec([]driver.Value) (driver.Result, error) {
+	return driver.RowsAffected(0), nil
+}
+
+func (s catalogStmt) Query(args []driver.Value) (driver.Rows, error) {
+	// the catalog queries about a table take its name as their only or first argument
+	table := ""
+	if len(args) > 0 {
+		table, _ = args[0].(string)
+	}
+	for _, catalog := range catalogRows {
+		if strings.Contains(s.query, catalog.key) {
+			columns, values := catalog.rows(table)
+			return &catalogResult{columns: columns, rows: values}, nil
+		}
+	}
+	return &catalogResult{columns: []string{"count"}}, nil
+}
+
+type catalogResult struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (r *catalogResult) Columns() []string {
+	return r.columns
+}
+
+func (*catalogResult) Close() error {
+	return nil
+}
+
+func (r *catalogResult) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+func init() {
+	sql.Register("catalog", catalogDriver{})
+}
+
+func TestDumpSchemaPostgres(t *testing.T) {
+	sqlDB, err := sql.Open("catalog", "")
+	if err != nil {
+		t.Fatalf("test error: %v", err)
+	}
+	defer sqlDB.Close()
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("test error: %v", err)
+	}
+	schema, err := migrationhandler.DumpSchema(migrationhandler.DBConfig{DB: db})
+	if err != nil {
+		t.Fatalf("expected: %+v, got: %+v", nil, err)
+	}
+	expected := "CREATE SEQUENCE \"orders_id_seq\" AS bigint INCREMENT BY 1 MINVALUE 1 MAXVALUE 9223372036854775807 START WITH 1;\n" +
+		"CREATE SEQUENCE \"users_id_seq\" AS bigint INCREMENT BY 1 MINVALUE 1 MAXVALUE 9223372036854775807 START WITH 1;\n" +
+		"CREATE TABLE \"orders\" (\n  \"id\" bigint NOT NULL DEFAULT nextval('orders_id_seq'::regclass),\n  \"user_id\" bigint\n);\n" +
+		"CREATE TABLE \"users\" (\n  \"id\" bigint NOT NULL DEFAULT nextval('users_id_seq'::regclass),\n  \"name\" character varying(64),\n" +
+		"  \"tag\" integer NOT NULL GENERATED ALWAYS AS IDENTITY\n);\n" +
+		"ALTER TABLE \"orders\" ADD CONSTRAINT \"orders_pkey\" PRIMARY KEY (id);\n" +
+		"ALTER TABLE \"users\" ADD CONSTRAINT \"users_pkey\" PRIMARY KEY (id);\n" +
+		"CREATE INDEX idx_users_name ON public.users USING btree (name);\n" +
+		"ALTER SEQUENCE \"users_id_seq\" OWNED BY \"users\".\"id\";\n" +
+		"ALTER TABLE \"orders\" ADD CONSTRAINT \"fk_orders_user\" FOREIGN KEY (user_id) REFERENCES users(id);\n"
+	if schema != expected {
+		t.Errorf("expected: %+v, got: %+v", expected, schema)
+	}
+}
@@ -0,0 +1,26 @@
+package migrationhandler
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+)
+
+// envReference matches the ${VAR} references replaced by ExpandEnv, a bare $ is left alone as SQL uses it for
+// placeholders and dollar quoting
+var envReference = regexp.MustCompile(`\$\{(\w+)\}`)
+
+// expandEnv replaces every ${VAR} reference of sql with the value of the environment variable, failing on the
+// first one that is not set
+func expandEnv(sql string) (string, error) {
+	var err error
+	expanded := envReference.ReplaceAllStringFunc(sql, func(reference string) string {
+		name := envReference.FindStringSubmatch(reference)[1]
+		value, ok := os.LookupEnv(name)
+		if !ok && err == nil {
+			err = fmt.Errorf("environment variable %s is not set", name)
+		}
+		return value
+	})
+	return expanded, err
+}
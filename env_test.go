@@ -0,0 +1,105 @@
+package migrationhandler_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	migrationhandler "github.com/jvfrodrigues/gorm-migration-handler"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestRunMigrationsExpandEnv(t *testing.T) {
+	tests := []struct {
+		name          string
+		env           map[string]string
+		expandEnv     bool
+		up            string
+		expectedError error
+		expectedTable string
+	}{
+		{
+			name:          "Test if environment variables are expanded",
+			env:           map[string]string{"EXPAND_ENV_TABLE": "expand_env_orders"},
+			expandEnv:     true,
+			up:            "CREATE TABLE ${EXPAND_ENV_TABLE} (id integer, price text DEFAULT '$1');",
+			expectedError: nil,
+			expectedTable: "expand_env_orders",
+		},
+		{
+			name:          "Test if it errors on variables that are not set",
+			env:           map[string]string{},
+			expandEnv:     true,
+			up:            "CREATE TABLE ${EXPAND_ENV_MISSING} (id integer);",
+			expectedError: errors.New("could not read file 1_orders_up.sql: environment variable EXPAND_ENV_MISSING is not set"),
+			expectedTable: "",
+		},
+		{
+			name:          "Test if files are not expanded by default",
+			env:           map[string]string{"EXPAND_ENV_TABLE": "expand_env_orders"},
+			expandEnv:     false,
+			up:            "CREATE TABLE \"${EXPAND_ENV_TABLE}\" (id integer);",
+			expectedError: nil,
+			expectedTable: "${EXPAND_ENV_TABLE}",
+		},
+	}
+	for i, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			for key, value := range tc.env {
+				t.Setenv(key, value)
+			}
+			dir := tempDir(t)
+			defer func() {
+				_ = os.RemoveAll(dir)
+			}()
+			dialector := sqlite.Open(fmt.Sprintf("file:expand_env_test_%d?mode=memory&cache=shared", i))
+			db, err := gorm.Open(dialector, &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+			if err != nil {
+				t.Fatalf("test error: %v", err)
+			}
+			writeMigration(t, dir, "1_orders_up.sql", tc.up)
+			writeMigration(t, dir, "1_orders_down.sql", "SELECT 1;")
+			_, err = migrationhandler.RunMigrations(migrationhandler.DBConfig{
+				Dialector:            dialector,
+				MigrationsFolderPath: "./" + dir,
+				ExpandEnv:            tc.expandEnv,
+			})
+			if err != nil || tc.expectedError != nil {
+				if err == nil || tc.expectedError == nil || err.Error() != tc.expectedError.Error() {
+					t.Errorf("expected: %+v, got: %+v", tc.expectedError, err)
+				}
+			}
+			if tc.expectedTable != "" && !db.Migrator().HasTable(tc.expectedTable) {
+				t.Errorf("expected table %s to be created", tc.expectedTable)
+			}
+		})
+	}
+}
+
+func TestRunMigrationsExpandEnvChecksum(t *testing.T) {
+	dir := tempDir(t)
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+	writeMigration(t, dir, "1_orders_up.sql", "CREATE TABLE ${EXPAND_ENV_CHECKSUM_TABLE} (id integer);")
+	writeMigration(t, dir, "1_orders_down.sql", "SELECT 1;")
+	dbConfig := migrationhandler.DBConfig{
+		Dialector:            sqlite.Open("file:expand_env_checksum_test?mode=memory&cache=shared"),
+		MigrationsFolderPath: "./" + dir,
+		ExpandEnv:            true,
+	}
+	t.Setenv("EXPAND_ENV_CHECKSUM_TABLE", "expand_env_before")
+	_, err := migrationhandler.RunMigrations(dbConfig)
+	if err != nil {
+		t.Fatalf("test error: %v", err)
+	}
+	// the file is the same, only the value of its variable changed
+	t.Setenv("EXPAND_ENV_CHECKSUM_TABLE", "expand_env_after")
+	_, err = migrationhandler.RunMigrations(dbConfig)
+	if err != nil {
+		t.Errorf("expected: %+v, got: %+v", nil, err)
+	}
+}
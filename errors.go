@@ -0,0 +1,17 @@
+package migrationhandler
+
+import "errors"
+
+// Errors wrapped by the errors of the package, so callers can tell outcomes apart with errors.Is
+var (
+	// ErrNoChanges is returned by CreateMigration when SkipEmpty is set and the auto migration found no changes
+	ErrNoChanges = errors.New("no auto changes found")
+	// ErrConnectionFailed is wrapped with the error of the driver when the database can not be opened
+	ErrConnectionFailed = errors.New("connection to database failed")
+	// ErrNoMigrations is wrapped when the migrations folder has no migrations to run, verify or squash
+	ErrNoMigrations = errors.New("no migrations")
+	// ErrFolderNotFound is wrapped with the error of the file system when the migrations folder does not exist
+	ErrFolderNotFound = errors.New("migrations folder not found")
+	// ErrMigrationNotFound is wrapped when a migration ID given to a function is not in the migrations folder
+	ErrMigrationNotFound = errors.New("migration not found")
+)
@@ -0,0 +1,78 @@
+package migrationhandler_test
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	migrationhandler "github.com/jvfrodrigues/gorm-migration-handler"
+	"gorm.io/driver/mysql"
+)
+
+func TestSentinelErrors(t *testing.T) {
+	dir := tempDir(t)
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+	dialector := sqlite.Open("file:sentinel_errors_test?mode=memory&cache=shared")
+	tests := []struct {
+		name          string
+		run           func() error
+		expectedError error
+	}{
+		{
+			name: "Test if a failed connection wraps ErrConnectionFailed",
+			run: func() error {
+				_, err := migrationhandler.RunMigrations(migrationhandler.DBConfig{
+					Dialector:            mysql.New(mysql.Config{DriverName: "my_mysql_driver", DSN: "gorm:gorm@tcp(localhost:9910)/gorm"}),
+					MigrationsFolderPath: "./" + dir,
+				})
+				return err
+			},
+			expectedError: migrationhandler.ErrConnectionFailed,
+		},
+		{
+			name: "Test if a missing folder wraps ErrFolderNotFound",
+			run: func() error {
+				_, err := migrationhandler.RunMigrations(migrationhandler.DBConfig{
+					Dialector:            dialector,
+					MigrationsFolderPath: "./non-existing-folder",
+				})
+				return err
+			},
+			expectedError: migrationhandler.ErrFolderNotFound,
+		},
+		{
+			name: "Test if an empty folder wraps ErrNoMigrations",
+			run: func() error {
+				_, err := migrationhandler.RunMigrations(migrationhandler.DBConfig{
+					Dialector:            dialector,
+					MigrationsFolderPath: "./" + dir,
+				})
+				return err
+			},
+			expectedError: migrationhandler.ErrNoMigrations,
+		},
+		{
+			name: "Test if an unknown migration ID wraps ErrMigrationNotFound",
+			run: func() error {
+				writeMigration(t, dir, "1_users_up.sql", "SELECT 1;")
+				writeMigration(t, dir, "1_users_down.sql", "SELECT 1;")
+				return migrationhandler.MigrateTo(migrationhandler.DBConfig{
+					Dialector:            dialector,
+					MigrationsFolderPath: "./" + dir,
+				}, "9")
+			},
+			expectedError: migrationhandler.ErrMigrationNotFound,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.run()
+			if !errors.Is(err, tc.expectedError) {
+				t.Errorf("expected: %+v, got: %+v", tc.expectedError, err)
+			}
+		})
+	}
+}
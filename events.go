@@ -0,0 +1,53 @@
+package migrationhandler
+
+import "time"
+
+// EventType is the kind of step of a migration an Event tells about
+type EventType string
+
+const (
+	// EventStarted is sent before a migration is applied or rolled back
+	EventStarted EventType = "started"
+	// EventApplied is sent after a migration is applied
+	EventApplied EventType = "applied"
+	// EventSkipped is sent for the migrations a run leaves out as they are already applied
+	EventSkipped EventType = "skipped"
+	// EventFailed is sent when applying or rolling back a migration fails
+	EventFailed EventType = "failed"
+	// EventRolledBack is sent after a migration is rolled back
+	EventRolledBack EventType = "rolled_back"
+)
+
+// Event is sent to OnEvent on every step of a migration, for metrics and tracing integrations
+type Event struct {
+	Type EventType
+	ID   string
+	Name string
+	// Rollback is set when the migration is being rolled back instead of applied
+	Rollback bool
+	// Duration is how long applying or rolling back took, set on applied, failed and rolled back events
+	Duration time.Duration
+	// Err is why the migration failed, set on failed events
+	Err error
+}
+
+// emit sends an event to OnEvent when it is set
+func (m *migrationManager) emit(event Event) {
+	if m.onEvent != nil {
+		m.onEvent(event)
+	}
+}
+
+// emitDone sends the event ending a migration that started at start, failed when err is set
+func (m *migrationManager) emitDone(migration migration, rollback bool, start time.Time, err error) {
+	event := Event{ID: migration.id, Name: migration.name, Rollback: rollback, Duration: time.Since(start), Err: err}
+	switch {
+	case err != nil:
+		event.Type = EventFailed
+	case rollback:
+		event.Type = EventRolledBack
+	default:
+		event.Type = EventApplied
+	}
+	m.emit(event)
+}
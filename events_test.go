@@ -0,0 +1,64 @@
+package migrationhandler_test
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	migrationhandler "github.com/jvfrodrigues/gorm-migration-handler"
+)
+
+func TestOnEvent(t *testing.T) {
+	tests := []struct {
+		name           string
+		upSQL          string
+		expectedEvents []string
+	}{
+		{
+			name:  "Test if events are sent for applied, skipped and rolled back migrations",
+			upSQL: "CREATE TABLE events_test (id integer);",
+			expectedEvents: []string{
+				"started 1 false", "applied 1 false",
+				"skipped 1 false",
+				"started 1 true", "rolled_back 1 true",
+			},
+		},
+		{
+			name:  "Test if a failing migration sends a failed event",
+			upSQL: "CREATE TABLE;",
+			expectedEvents: []string{
+				"started 1 false", "failed 1 false",
+			},
+		},
+	}
+	for i, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := tempDir(t)
+			defer func() {
+				_ = os.RemoveAll(dir)
+			}()
+			events := make([]string, 0)
+			dbConfig := migrationhandler.DBConfig{
+				Dialector:            sqlite.Open(fmt.Sprintf("file:events_test_%v?mode=memory&cache=shared", i)),
+				MigrationsFolderPath: "./" + dir,
+				OnEvent: func(event migrationhandler.Event) {
+					events = append(events, fmt.Sprintf("%s %s %v", event.Type, event.ID, event.Rollback))
+					if (event.Type == migrationhandler.EventFailed) != (event.Err != nil) {
+						t.Errorf("expected an error only on failed events, got: %+v", event)
+					}
+				},
+			}
+			writeMigration(t, dir, "1_events_up.sql", tc.upSQL)
+			writeMigration(t, dir, "1_events_down.sql", "DROP TABLE events_test;")
+			_, err := migrationhandler.RunMigrations(dbConfig)
+			if err == nil {
+				_, _ = migrationhandler.RunMigrations(dbConfig)
+				_, _ = migrationhandler.RollbackMigration(dbConfig)
+			}
+			if fmt.Sprint(events) != fmt.Sprint(tc.expectedEvents) {
+				t.Errorf("expected: %+v, got: %+v", tc.expectedEvents, events)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package migrationhandler
+
+import "fmt"
+
+// FakeApply gets DB info and gets all migrations from given folder to record the migration with the given ID as
+// applied without running its SQL, for SQL already applied by hand like during incident recovery. Pending
+// migrations before it are reported as out of order by OutOfOrderCheck, so faking it ahead of them has to be
+// allowed with CheckWarn or CheckIgnore. On DryRun it only logs the migration it would record
+func FakeApply(dbConfig DBConfig, migrationID string) error {
+	db, err := newDatabase(dbConfig)
+	if err != nil {
+		return fmt.Errorf("%w, can not fake migrations: %w", ErrConnectionFailed, err)
+	}
+	migrations, err := getMigrations(dbConfig)
+	if err != nil {
+		return err
+	}
+	var found *migration
+	for i := range migrations {
+		if migrations[i].id == migrationID {
+			found = &migrations[i]
+		}
+	}
+	if found == nil {
+		return fmt.Errorf("%w: %s", ErrMigrationNotFound, migrationID)
+	}
+	options := managerOptions(dbConfig)
+	if !dbConfig.DryRun {
+		release, err := lockMigrations(db, dbConfig, options)
+		if err != nil {
+			return err
+		}
+		defer release()
+	}
+	applied, err := getAppliedMigrations(db, options)
+	if err != nil {
+		return err
+	}
+	if _, ok := applied[migrationID]; ok {
+		return fmt.Errorf("migration %s_%s is already applied", found.id, found.name)
+	}
+	logger := getLogger(dbConfig)
+	for _, migration := range migrations[:migrationIndex(migrations, migrationID)] {
+		if _, ok := applied[migration.id]; ok {
+			continue
+		}
+		err = dbConfig.OutOfOrderCheck.report(logger, fmt.Errorf("migration %s_%s is pending before migration %s, faking it would apply them out of order", migration.id, migration.name, migrationID))
+		if err != nil {
+			return err
+		}
+	}
+	if dbConfig.DryRun {
+		logger.Info("Migration '%s_%s' would be recorded as applied", found.id, found.name)
+		return nil
+	}
+	err = found.loadUp()
+	if err != nil {
+		return err
+	}
+	err = recordMigrations(db.Db, options, []migration{*found})
+	if err != nil {
+		return fmt.Errorf("could not record migrations: %w", err)
+	}
+	logger.Info("Migration '%s_%s' recorded as applied without running it", found.id, found.name)
+	return nil
+}
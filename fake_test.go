@@ -0,0 +1,95 @@
+package migrationhandler_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	migrationhandler "github.com/jvfrodrigues/gorm-migration-handler"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestFakeApply(t *testing.T) {
+	tests := []struct {
+		name            string
+		migrationID     string
+		outOfOrderCheck migrationhandler.CheckMode
+		expectedError   error
+		expectedApplied []string
+	}{
+		{
+			name:            "Test if the next pending migration is recorded without running",
+			migrationID:     "2",
+			expectedApplied: []string{"1", "2"},
+		},
+		{
+			name:            "Test if it refuses to fake a migration ahead of pending ones",
+			migrationID:     "3",
+			expectedError:   errors.New("migration 2_second is pending before migration 3, faking it would apply them out of order"),
+			expectedApplied: []string{"1"},
+		},
+		{
+			name:            "Test if faking ahead of pending migrations can be forced",
+			migrationID:     "3",
+			outOfOrderCheck: migrationhandler.CheckIgnore,
+			expectedApplied: []string{"1", "3"},
+		},
+		{
+			name:            "Test if it errors on an applied migration",
+			migrationID:     "1",
+			expectedError:   errors.New("migration 1_first is already applied"),
+			expectedApplied: []string{"1"},
+		},
+		{
+			name:            "Test if it errors on non existing migration ID",
+			migrationID:     "9",
+			expectedError:   errors.New("migration not found: 9"),
+			expectedApplied: []string{"1"},
+		},
+	}
+	for i, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dialector := sqlite.Open(fmt.Sprintf("file:fake_test_%d?mode=memory&cache=shared", i))
+			db, err := gorm.Open(dialector, &gorm.Config{
+				Logger: logger.Default.LogMode(logger.Silent),
+			})
+			if err != nil {
+				t.Fatalf("test error: %v", err)
+			}
+			dir := tempDir(t)
+			defer func() {
+				_ = os.RemoveAll(dir)
+			}()
+			dbConfig := migrationhandler.DBConfig{
+				Dialector:            dialector,
+				MigrationsFolderPath: "./" + dir,
+			}
+			writeMigration(t, dir, "1_first_up.sql", "CREATE TABLE fake_first (id integer);")
+			writeMigration(t, dir, "1_first_down.sql", "DROP TABLE fake_first;")
+			err = migrationhandler.MigrateTo(dbConfig, "1")
+			if err != nil {
+				t.Fatalf("test error: %v", err)
+			}
+			// a failing SQL shows the faked migrations are not run
+			writeMigration(t, dir, "2_second_up.sql", "CREATE TABLE;")
+			writeMigration(t, dir, "2_second_down.sql", "SELECT 1;")
+			writeMigration(t, dir, "3_third_up.sql", "CREATE TABLE;")
+			writeMigration(t, dir, "3_third_down.sql", "SELECT 1;")
+			dbConfig.OutOfOrderCheck = tc.outOfOrderCheck
+			err = migrationhandler.FakeApply(dbConfig, tc.migrationID)
+			if err != nil || tc.expectedError != nil {
+				if err == nil || tc.expectedError == nil || err.Error() != tc.expectedError.Error() {
+					t.Errorf("expected: %+v, got: %+v", tc.expectedError, err)
+				}
+			}
+			applied := make([]string, 0)
+			db.Table("migrations").Order("id").Pluck("id", &applied)
+			if fmt.Sprint(applied) != fmt.Sprint(tc.expectedApplied) {
+				t.Errorf("expected applied: %+v, got: %+v", tc.expectedApplied, applied)
+			}
+		})
+	}
+}
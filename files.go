@@ -0,0 +1,176 @@
+package migrationhandler
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+var migrationIDFilter = regexp.MustCompile(`^\d+`)
+
+var migrationNameFilter = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// validateMigrationName checks that the name of a new migration only has letters, digits, underscores and hyphens
+// and that its files are read back as its up and down files: it can not end with the suffix of up or down files
+// without its extension, like "_up", and neither file can match the suffix or pattern of the other kind
+func validateMigrationName(dbConfig DBConfig, migrationName string) error {
+	if !migrationNameFilter.MatchString(migrationName) {
+		return fmt.Errorf("invalid migration name %q, use only letters, digits, underscores and hyphens", migrationName)
+	}
+	upSuffix, downSuffix := fileSuffixes(dbConfig)
+	for _, suffix := range []string{upSuffix, downSuffix} {
+		token := strings.TrimSuffix(suffix, path.Ext(suffix))
+		if token != "" && strings.HasSuffix(strings.ToLower(migrationName), strings.ToLower(token)) {
+			return fmt.Errorf("invalid migration name %q, it can not end with %q which ends the names of migration files", migrationName, token)
+		}
+	}
+	matcher, err := newFileMatcher(dbConfig)
+	if err != nil {
+		return err
+	}
+	// the ID does not change how a file is matched, so any one will do
+	upFile, downFile := "1_"+migrationName+upSuffix, "1_"+migrationName+downSuffix
+	if _, _, _, isMigration, ok := matcher.match(upFile); ok && !isMigration {
+		return fmt.Errorf("invalid migration name %q, its up file %s would be read as a down file", migrationName, upFile)
+	}
+	if _, _, _, isMigration, ok := matcher.match(downFile); ok && isMigration {
+		return fmt.Errorf("invalid migration name %q, its down file %s would be read as an up file", migrationName, downFile)
+	}
+	return nil
+}
+
+// fileMatcher tells up and down files apart and reads the ID and name of their migration from their name,
+// either by their suffix or by the pattern configured for them
+type fileMatcher struct {
+	dialect     string
+	upSuffix    string
+	downSuffix  string
+	upPattern   *regexp.Regexp
+	downPattern *regexp.Regexp
+}
+
+func newFileMatcher(dbConfig DBConfig) (*fileMatcher, error) {
+	upSuffix, downSuffix := fileSuffixes(dbConfig)
+	matcher := &fileMatcher{
+		upSuffix:   upSuffix,
+		downSuffix: downSuffix,
+	}
+	if dbConfig.DB != nil {
+		matcher.dialect = dbConfig.DB.Dialector.Name()
+	} else if dbConfig.Dialector != nil {
+		matcher.dialect = dbConfig.Dialector.Name()
+	}
+	var err error
+	matcher.upPattern, err = compileFilePattern("up", dbConfig.UpPattern)
+	if err != nil {
+		return nil, err
+	}
+	matcher.downPattern, err = compileFilePattern("down", dbConfig.DownPattern)
+	if err != nil {
+		return nil, err
+	}
+	return matcher, nil
+}
+
+// compileFilePattern compiles a configured file pattern, which needs an id group for the ID of the migration
+func compileFilePattern(kind string, pattern string) (*regexp.Regexp, error) {
+	if pattern == "" {
+		return nil, nil
+	}
+	compiled, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s pattern: %w", kind, err)
+	}
+	if compiled.SubexpIndex("id") < 0 {
+		return nil, fmt.Errorf("invalid %s pattern: %s has no (?P<id>...) group", kind, pattern)
+	}
+	return compiled, nil
+}
+
+// match checks if fileName is an up or down file, returning the ID and name of its migration and its dialect.
+// When both kinds match the one with the longest suffix wins, so "_up.sql" and ".sql" can be told apart
+func (f *fileMatcher) match(fileName string) (string, string, string, bool, bool) {
+	upID, upName, upDialect, upEnd, isMigration := f.matchKind(fileName, f.upSuffix, f.upPattern)
+	downID, downName, downDialect, downEnd, isRollback := f.matchKind(fileName, f.downSuffix, f.downPattern)
+	if isRollback && (!isMigration || downEnd < upEnd) {
+		return downID, downName, downDialect, false, true
+	}
+	return upID, upName, upDialect, true, isMigration
+}
+
+// matchKind matches fileName with the pattern of a kind of file, or its suffix when there is no pattern,
+// also returning where the suffix starts
+func (f *fileMatcher) matchKind(fileName string, suffix string, pattern *regexp.Regexp) (string, string, string, int, bool) {
+	if pattern != nil {
+		match := pattern.FindStringSubmatchIndex(fileName)
+		if match == nil {
+			return "", "", "", 0, false
+		}
+		idIndex, nameIndex := pattern.SubexpIndex("id"), pattern.SubexpIndex("name")
+		// an optional id group can be left out of the match, the file then has no ID to run it by
+		if match[2*idIndex] < 0 {
+			return "", "", "", 0, false
+		}
+		migrationID := fileName[match[2*idIndex]:match[2*idIndex+1]]
+		end := match[2*idIndex+1]
+		migrationName := ""
+		if nameIndex >= 0 && match[2*nameIndex] >= 0 {
+			migrationName = fileName[match[2*nameIndex]:match[2*nameIndex+1]]
+			end = max(end, match[2*nameIndex+1])
+		}
+		return migrationID, migrationName, "", end, migrationID != ""
+	}
+	baseName, fileDialect, ok := matchFileName(fileName, suffix, f.dialect)
+	if !ok || !migrationIDFilter.MatchString(baseName) {
+		return "", "", "", 0, false
+	}
+	migrationID, migrationName := parseFileName(baseName)
+	return migrationID, migrationName, fileDialect, len(baseName), true
+}
+
+// hasSuffix checks if fileName ends like an up or down file, dialect files included
+func (f *fileMatcher) hasSuffix(fileName string) bool {
+	for _, suffix := range []string{f.upSuffix, f.downSuffix} {
+		if _, _, ok := matchFileName(fileName, suffix, f.dialect); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// parseFileName splits the name of a migration file without its up or down suffix into its ID,
+// everything before the first underscore, and its name, everything after it
+func parseFileName(baseName string) (string, string) {
+	migrationID, migrationName, _ := strings.Cut(baseName, "_")
+	return migrationID, migrationName
+}
+
+// matchFileName checks if fileName ends with suffix, returning the name before it. Dialect files, which
+// have the dialect before the extension of the suffix like 1_name_up.postgres.sql, only match for their dialect
+func matchFileName(fileName string, suffix string, dialect string) (string, string, bool) {
+	if strings.HasSuffix(fileName, suffix) {
+		return strings.TrimSuffix(fileName, suffix), "", true
+	}
+	if dialect == "" {
+		return "", "", false
+	}
+	extension := path.Ext(suffix)
+	dialectSuffix := strings.TrimSuffix(suffix, extension) + "." + dialect + extension
+	if strings.HasSuffix(fileName, dialectSuffix) {
+		return strings.TrimSuffix(fileName, dialectSuffix), dialect, true
+	}
+	return "", "", false
+}
+
+// fileSuffixes returns the configured suffixes of up and down files, or their defaults
+func fileSuffixes(dbConfig DBConfig) (string, string) {
+	upSuffix, downSuffix := DefaultUpSuffix, DefaultDownSuffix
+	if dbConfig.UpSuffix != "" {
+		upSuffix = dbConfig.UpSuffix
+	}
+	if dbConfig.DownSuffix != "" {
+		downSuffix = dbConfig.DownSuffix
+	}
+	return upSuffix, downSuffix
+}
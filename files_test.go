@@ -0,0 +1,94 @@
+package migrationhandler_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"testing/fstest"
+
+	"github.com/glebarez/sqlite"
+	migrationhandler "github.com/jvfrodrigues/gorm-migration-handler"
+)
+
+func TestMigrationPatterns(t *testing.T) {
+	files := fstest.MapFS{
+		"migrations/V1__init_up.sql":      {Data: []byte("CREATE TABLE patterns_init (id integer);")},
+		"migrations/V1__init_down.sql":    {Data: []byte("DROP TABLE patterns_init;")},
+		"migrations/V2__users_up.sql":     {Data: []byte("CREATE TABLE patterns_users (id integer);")},
+		"migrations/V2__users_down.sql":   {Data: []byte("DROP TABLE patterns_users;")},
+		"migrations/3_default_up.sql":     {Data: []byte("CREATE TABLE patterns_default (id integer);")},
+		"migrations/3_default_down.sql":   {Data: []byte("DROP TABLE patterns_default;")},
+		"migrations/init_up.sql":          {Data: []byte("CREATE TABLE patterns_unversioned (id integer);")},
+		"migrations/init_down.sql":        {Data: []byte("DROP TABLE patterns_unversioned;")},
+		"migrations/README.md":            {Data: []byte("legacy migrations")},
+		"migrations/V3__notes_readme.txt": {Data: []byte("")},
+	}
+	tests := []struct {
+		name            string
+		upPattern       string
+		downPattern     string
+		expectedApplied []migrationhandler.AppliedMigration
+		expectedError   error
+	}{
+		{
+			name:        "Test if migrations are found with custom patterns",
+			upPattern:   `^V(?P<id>\d+)__(?P<name>.+)_up\.sql$`,
+			downPattern: `^V(?P<id>\d+)__(?P<name>.+)_down\.sql$`,
+			expectedApplied: []migrationhandler.AppliedMigration{
+				{ID: "1", Name: "init"},
+				{ID: "2", Name: "users"},
+			},
+			expectedError: nil,
+		},
+		{
+			name:        "Test if files are left out when an optional id group does not match",
+			upPattern:   `^(?:V(?P<id>\d+)__)?(?P<name>.+)_up\.sql$`,
+			downPattern: `^(?:V(?P<id>\d+)__)?(?P<name>.+)_down\.sql$`,
+			expectedApplied: []migrationhandler.AppliedMigration{
+				{ID: "1", Name: "init"},
+				{ID: "2", Name: "users"},
+			},
+			expectedError: nil,
+		},
+		{
+			name:        "Test if the default naming is used without patterns",
+			upPattern:   "",
+			downPattern: "",
+			expectedApplied: []migrationhandler.AppliedMigration{
+				{ID: "3", Name: "default"},
+			},
+			expectedError: nil,
+		},
+		{
+			name:          "Test if it errors on patterns that do not compile",
+			upPattern:     `^V(?P<id>\d+`,
+			expectedError: errors.New("invalid up pattern: error parsing regexp: missing closing ): `^V(?P<id>\\d+`"),
+		},
+		{
+			name:          "Test if it errors on patterns without an id group",
+			upPattern:     `^V\d+__.+_up\.sql$`,
+			downPattern:   `^V(?P<id>\d+)__(?P<name>.+)_down\.sql$`,
+			expectedError: errors.New("invalid up pattern: ^V\\d+__.+_up\\.sql$ has no (?P<id>...) group"),
+		},
+	}
+	for i, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			applied, err := migrationhandler.RunMigrations(migrationhandler.DBConfig{
+				Dialector:            sqlite.Open(fmt.Sprintf("file:patterns_test_%v?mode=memory&cache=shared", i)),
+				MigrationsFS:         files,
+				MigrationsFolderPath: "migrations",
+				UpPattern:            tc.upPattern,
+				DownPattern:          tc.downPattern,
+			})
+			if err != nil || tc.expectedError != nil {
+				if err == nil || tc.expectedError == nil || err.Error() != tc.expectedError.Error() {
+					t.Errorf("expected: %+v, got: %+v", tc.expectedError, err)
+				}
+				return
+			}
+			if fmt.Sprint(withoutDurations(applied)) != fmt.Sprint(tc.expectedApplied) {
+				t.Errorf("expected: %+v, got: %+v", tc.expectedApplied, applied)
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package migrationhandler
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// GoMigration is a migration written in Go, for data changes SQL can not express well like iterating rows.
+// Migrate and Rollback run in the transaction of the migration, a nil Rollback makes it irreversible
+type GoMigration struct {
+	ID       string
+	Name     string
+	Migrate  func(tx *gorm.DB) error
+	Rollback func(tx *gorm.DB) error
+}
+
+// addGoMigrations merges the GoMigrations of the dbConfig with the migrations read from files, an ID and
+// name used by both is an error and the same ID with different names is reported by validateIDs
+func addGoMigrations(dbConfig DBConfig, migrations map[string]migration) error {
+	for _, goMigration := range dbConfig.GoMigrations {
+		if goMigration.ID == "" || goMigration.Migrate == nil {
+			return fmt.Errorf("Go migration %s_%s needs an ID and a Migrate function", goMigration.ID, goMigration.Name)
+		}
+		migrationKey := goMigration.ID + "_" + goMigration.Name
+		if _, ok := migrations[migrationKey]; ok {
+			return fmt.Errorf("migration %s is defined both in Go and in files", migrationKey)
+		}
+		migrations[migrationKey] = migration{
+			id:           goMigration.ID,
+			name:         goMigration.Name,
+			goMigrate:    goMigration.Migrate,
+			goRollback:   goMigration.Rollback,
+			irreversible: goMigration.Rollback == nil,
+		}
+	}
+	return nil
+}
+
+// runUp runs the Go function of the migration or the statements of its up file after transform
+func (m migration) runUp(db *gorm.DB, transform func(sql string) string) error {
+	if m.goMigrate != nil {
+		return m.goMigrate(db)
+	}
+	return execStatements(db, transform(m.migrationSQL))
+}
+
+// runDown runs the Go rollback function of the migration or the statements of its down file after transform
+func (m migration) runDown(db *gorm.DB, transform func(sql string) string) error {
+	if m.goMigrate != nil {
+		return m.goRollback(db)
+	}
+	return execStatements(db, transform(m.rollbackSQL))
+}
+
+// describeUp is what a dry run logs for the migration, the SQL of its up file after transform
+func (m migration) describeUp(transform func(sql string) string) string {
+	if m.goMigrate != nil {
+		return "-- Go migration"
+	}
+	return transform(m.migrationSQL)
+}
+
+// describeDown is the SQL of the down file of the migration after transform
+func (m migration) describeDown(transform func(sql string) string) string {
+	if m.goMigrate != nil {
+		return "-- Go migration"
+	}
+	return transform(m.rollbackSQL)
+}
@@ -0,0 +1,111 @@
+package migrationhandler_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	migrationhandler "github.com/jvfrodrigues/gorm-migration-handler"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestRunMigrationsGo(t *testing.T) {
+	backfill := migrationhandler.GoMigration{
+		ID:   "2",
+		Name: "backfill",
+		Migrate: func(tx *gorm.DB) error {
+			for id := 1; id <= 3; id++ {
+				err := tx.Exec("INSERT INTO go_users (id) VALUES (?)", id).Error
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Rollback: func(tx *gorm.DB) error {
+			return tx.Exec("DELETE FROM go_users").Error
+		},
+	}
+	tests := []struct {
+		name            string
+		goMigrations    []migrationhandler.GoMigration
+		expectedError   error
+		expectedApplied []migrationhandler.AppliedMigration
+		expectedRows    int64
+	}{
+		{
+			name:         "Test if Go migrations run in order with the ones of the folder",
+			goMigrations: []migrationhandler.GoMigration{backfill},
+			expectedApplied: []migrationhandler.AppliedMigration{
+				{ID: "1", Name: "users"},
+				{ID: "2", Name: "backfill"},
+				{ID: "3", Name: "emails"},
+			},
+			expectedRows: 3,
+		},
+		{
+			name:          "Test if it errors on a Go migration defined in files too",
+			goMigrations:  []migrationhandler.GoMigration{{ID: "1", Name: "users", Migrate: backfill.Migrate}},
+			expectedError: errors.New("migration 1_users is defined both in Go and in files"),
+		},
+		{
+			name:          "Test if it errors on a Go migration sharing an ID",
+			goMigrations:  []migrationhandler.GoMigration{{ID: "1", Name: "backfill", Migrate: backfill.Migrate}},
+			expectedError: errors.New("duplicated migration IDs: 1 (Go migration 1_backfill, 1_users_up.sql)"),
+		},
+		{
+			name:          "Test if it errors on a Go migration without a Migrate function",
+			goMigrations:  []migrationhandler.GoMigration{{ID: "2", Name: "backfill"}},
+			expectedError: errors.New("Go migration 2_backfill needs an ID and a Migrate function"),
+		},
+	}
+	for i, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := tempDir(t)
+			defer func() {
+				_ = os.RemoveAll(dir)
+			}()
+			dialector := sqlite.Open(fmt.Sprintf("file:go_migration_test_%d?mode=memory&cache=shared", i))
+			db, err := gorm.Open(dialector, &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+			if err != nil {
+				t.Fatalf("test error: %v", err)
+			}
+			dbConfig := migrationhandler.DBConfig{
+				Dialector:            dialector,
+				MigrationsFolderPath: "./" + dir,
+				GoMigrations:         tc.goMigrations,
+			}
+			writeMigration(t, dir, "1_users_up.sql", "CREATE TABLE go_users (id integer);")
+			writeMigration(t, dir, "1_users_down.sql", "DROP TABLE go_users;")
+			writeMigration(t, dir, "3_emails_up.sql", "ALTER TABLE go_users ADD COLUMN email text;")
+			writeMigration(t, dir, "3_emails_down.sql", "ALTER TABLE go_users DROP COLUMN email;")
+			applied, err := migrationhandler.RunMigrations(dbConfig)
+			if err != nil || tc.expectedError != nil {
+				if err == nil || tc.expectedError == nil || err.Error() != tc.expectedError.Error() {
+					t.Errorf("expected: %+v, got: %+v", tc.expectedError, err)
+				}
+				return
+			}
+			if !reflect.DeepEqual(withoutDurations(applied), tc.expectedApplied) {
+				t.Errorf("expected: %+v, got: %+v", tc.expectedApplied, applied)
+			}
+			var rows int64
+			db.Table("go_users").Count(&rows)
+			if rows != tc.expectedRows {
+				t.Errorf("expected: %+v, got: %+v", tc.expectedRows, rows)
+			}
+			err = migrationhandler.RollbackTo(dbConfig, "1")
+			if err != nil {
+				t.Fatalf("expected: %+v, got: %+v", nil, err)
+			}
+			db.Table("go_users").Count(&rows)
+			if rows != 0 {
+				t.Errorf("expected the Go rollback to delete every row, got: %+v", rows)
+			}
+		})
+	}
+}
@@ -0,0 +1,21 @@
+package migrationhandler
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// guardPasses runs the query of the "-- guard: <query>" directive of a migration, which must return a single
+// boolean telling if the migration should run
+func (m *migrationManager) guardPasses(tx *gorm.DB, migration migration) (bool, error) {
+	if migration.guard == "" {
+		return true, nil
+	}
+	var passes bool
+	err := tx.Raw(m.transformSQL(migration.guard)).Row().Scan(&passes)
+	if err != nil {
+		return false, fmt.Errorf("guard of migration %s_%s failed: %w", migration.id, migration.name, err)
+	}
+	return passes, nil
+}
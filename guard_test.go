@@ -0,0 +1,79 @@
+package migrationhandler_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	migrationhandler "github.com/jvfrodrigues/gorm-migration-handler"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestRunMigrationsGuard(t *testing.T) {
+	tests := []struct {
+		name            string
+		up              string
+		expectedError   error
+		expectedColumn  bool
+		expectedApplied int
+	}{
+		{
+			name:            "Test if a migration runs when its guard is true",
+			up:              "-- guard: SELECT COUNT(*) = 0 FROM pragma_table_info('guard_test') WHERE name = 'email'\nALTER TABLE guard_test ADD COLUMN email text;",
+			expectedError:   nil,
+			expectedColumn:  true,
+			expectedApplied: 1,
+		},
+		{
+			name:            "Test if a migration is recorded without running when its guard is false",
+			up:              "-- guard: SELECT COUNT(*) = 0 FROM pragma_table_info('guard_test') WHERE name = 'id'\nALTER TABLE guard_test ADD COLUMN email text;",
+			expectedError:   nil,
+			expectedColumn:  false,
+			expectedApplied: 1,
+		},
+		{
+			name:            "Test if a failing guard fails the migration",
+			up:              "-- guard: SELECT missing FROM guard_test\nALTER TABLE guard_test ADD COLUMN email text;",
+			expectedError:   errors.New("guard of migration 1_email failed: SQL logic error: no such column: missing (1)"),
+			expectedColumn:  false,
+			expectedApplied: 0,
+		},
+	}
+	for i, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := tempDir(t)
+			defer func() {
+				_ = os.RemoveAll(dir)
+			}()
+			dialector := sqlite.Open(fmt.Sprintf("file:guard_test_%v?mode=memory&cache=shared", i))
+			db, err := gorm.Open(dialector, &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+			if err != nil {
+				t.Fatalf("test error: %v", err)
+			}
+			err = db.Exec("CREATE TABLE guard_test (id integer)").Error
+			if err != nil {
+				t.Fatalf("test error: %v", err)
+			}
+			writeMigration(t, dir, "1_email_up.sql", tc.up)
+			writeMigration(t, dir, "1_email_down.sql", "SELECT 1;")
+			applied, err := migrationhandler.RunMigrations(migrationhandler.DBConfig{
+				Dialector:            dialector,
+				MigrationsFolderPath: "./" + dir,
+			})
+			if err != nil || tc.expectedError != nil {
+				if err == nil || tc.expectedError == nil || err.Error() != tc.expectedError.Error() {
+					t.Errorf("expected: %+v, got: %+v", tc.expectedError, err)
+				}
+			}
+			if len(applied) != tc.expectedApplied {
+				t.Errorf("expected: %+v, got: %+v", tc.expectedApplied, len(applied))
+			}
+			if db.Migrator().HasColumn("guard_test", "email") != tc.expectedColumn {
+				t.Errorf("expected column email to exist: %+v", tc.expectedColumn)
+			}
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package migrationhandler
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// Hook runs on the database around a batch of migrations, like toggling the foreign_keys pragma on SQLite.
+// An error returned by a hook aborts the operation
+type Hook func(db *gorm.DB) error
+
+// withHooks runs fn between the before and after hooks. The after hook runs even when fn fails,
+// so it can undo what the before hook did, but the error of fn is the one returned
+func withHooks(db *database, before Hook, after Hook, fn func() error) error {
+	if before != nil {
+		err := before(db.Db)
+		if err != nil {
+			return fmt.Errorf("before hook failed: %w", err)
+		}
+	}
+	err := fn()
+	if after != nil {
+		afterErr := after(db.Db)
+		if err == nil && afterErr != nil {
+			return fmt.Errorf("after hook failed: %w", afterErr)
+		}
+	}
+	return err
+}
@@ -0,0 +1,74 @@
+package migrationhandler_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	migrationhandler "github.com/jvfrodrigues/gorm-migration-handler"
+	"gorm.io/gorm"
+)
+
+func TestHooks(t *testing.T) {
+	tests := []struct {
+		name          string
+		beforeError   error
+		expectedError error
+		expectedCalls []string
+	}{
+		{
+			name:          "Test if hooks run around migrations and rollbacks",
+			beforeError:   nil,
+			expectedError: nil,
+			expectedCalls: []string{"before migrate", "after migrate", "before rollback", "after rollback"},
+		},
+		{
+			name:          "Test if a failing hook aborts the operation",
+			beforeError:   errors.New("pragma failed"),
+			expectedError: errors.New("before hook failed: pragma failed"),
+			expectedCalls: []string{"before migrate"},
+		},
+	}
+	for i, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := tempDir(t)
+			defer func() {
+				_ = os.RemoveAll(dir)
+			}()
+			calls := make([]string, 0)
+			hook := func(name string, err error) migrationhandler.Hook {
+				return func(db *gorm.DB) error {
+					calls = append(calls, name)
+					return err
+				}
+			}
+			dbConfig := migrationhandler.DBConfig{
+				Dialector:            sqlite.Open(fmt.Sprintf("file:hooks_test_%v?mode=memory&cache=shared", i)),
+				MigrationsFolderPath: "./" + dir,
+				BeforeMigrate:        hook("before migrate", tc.beforeError),
+				AfterMigrate:         hook("after migrate", nil),
+				BeforeRollback:       hook("before rollback", nil),
+				AfterRollback:        hook("after rollback", nil),
+			}
+			writeMigration(t, dir, "1_hooks_up.sql", "CREATE TABLE hooks_test (id integer);")
+			writeMigration(t, dir, "1_hooks_down.sql", "DROP TABLE hooks_test;")
+			_, err := migrationhandler.RunMigrations(dbConfig)
+			if err != nil || tc.expectedError != nil {
+				if err == nil || tc.expectedError == nil || err.Error() != tc.expectedError.Error() {
+					t.Errorf("expected: %+v, got: %+v", tc.expectedError, err)
+				}
+			}
+			if err == nil {
+				_, err = migrationhandler.RollbackMigration(dbConfig)
+				if err != nil {
+					t.Fatalf("expected: %+v, got: %+v", nil, err)
+				}
+			}
+			if fmt.Sprint(calls) != fmt.Sprint(tc.expectedCalls) {
+				t.Errorf("expected: %+v, got: %+v", tc.expectedCalls, calls)
+			}
+		})
+	}
+}
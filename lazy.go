@@ -0,0 +1,113 @@
+package migrationhandler
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// openFile opens a file of the migrations folder, from fsys when it is set
+func openFile(fsys fs.FS, folderPath string, fileName string) (io.ReadCloser, error) {
+	if fsys == nil {
+		return os.Open(filepath.Join(folderPath, fileName))
+	}
+	return fsys.Open(path.Join(folderPath, fileName))
+}
+
+// readHeader reads the header of a migration file, the blank and comment lines before its first statement,
+// which is where directives are, without reading the rest of the file
+func readHeader(fsys fs.FS, folderPath string, fileName string) (string, error) {
+	file, err := openFile(fsys, folderPath, fileName)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	var header strings.Builder
+	reader := bufio.NewReader(file)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return "", err
+		}
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "--") {
+			break
+		}
+		header.WriteString(line + "\n")
+		if err == io.EOF {
+			break
+		}
+	}
+	return header.String(), nil
+}
+
+// fileSource returns a function reading the whole content of a migration file when called
+func fileSource(fsys fs.FS, folderPath string, fileName string) func() (string, error) {
+	return func() (string, error) {
+		content, err := readFile(fsys, folderPath, fileName)
+		return string(content), err
+	}
+}
+
+// load reads the content of the up and down files of a migration, which are only read when their SQL is
+// needed so folders with a long history do not keep every file in memory
+func (m *migration) load() error {
+	err := m.loadUp()
+	if err != nil {
+		return err
+	}
+	return m.loadDown()
+}
+
+// loadUp reads the content of the up file into migrationSQL
+func (m *migration) loadUp() error {
+	if m.upSource == nil {
+		return nil
+	}
+	content, err := m.upSource()
+	if err != nil {
+		return fmt.Errorf("could not read file %s: %w", m.upFile, err)
+	}
+	m.upChecksum = checksum(content)
+	if m.expandEnv {
+		content, err = expandEnv(content)
+		if err != nil {
+			return fmt.Errorf("could not read file %s: %w", m.upFile, err)
+		}
+	}
+	m.migrationSQL, m.upSource = content, nil
+	return nil
+}
+
+// fileChecksum is the checksum of the up file as written, before ExpandEnv replaces its variables, or of
+// migrationSQL for migrations that were not read from a file
+func (m migration) fileChecksum() string {
+	if m.upChecksum != "" {
+		return m.upChecksum
+	}
+	return checksum(m.migrationSQL)
+}
+
+// loadDown reads the content of the down file into rollbackSQL
+func (m *migration) loadDown() error {
+	if m.downSource == nil {
+		return nil
+	}
+	content, err := m.downSource()
+	if err != nil {
+		return fmt.Errorf("could not read file %s: %w", m.downFile, err)
+	}
+	if m.expandEnv {
+		content, err = expandEnv(content)
+		if err != nil {
+			return fmt.Errorf("could not read file %s: %w", m.downFile, err)
+		}
+	}
+	m.rollbackSQL, m.downSource = content, nil
+	return nil
+}
@@ -0,0 +1,72 @@
+package migrationhandler_test
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"testing"
+	"testing/fstest"
+
+	"github.com/glebarez/sqlite"
+	migrationhandler "github.com/jvfrodrigues/gorm-migration-handler"
+)
+
+// readCountingFS records the files read whole, telling them apart from the ones only opened to read their header
+type readCountingFS struct {
+	fstest.MapFS
+	read []string
+}
+
+func (f *readCountingFS) ReadFile(name string) ([]byte, error) {
+	f.read = append(f.read, path.Base(name))
+	return fs.ReadFile(f.MapFS, name)
+}
+
+func TestRunMigrationsReadsOnlyNeededFiles(t *testing.T) {
+	tests := []struct {
+		name          string
+		checksumCheck migrationhandler.CheckMode
+		expectedRead  []string
+	}{
+		{
+			name:          "Test if only the pending migration is read",
+			checksumCheck: migrationhandler.CheckIgnore,
+			expectedRead:  []string{"3_third_up.sql"},
+		},
+		{
+			name:          "Test if applied up files are read to verify their checksums",
+			checksumCheck: migrationhandler.CheckError,
+			expectedRead:  []string{"1_first_up.sql", "2_second_up.sql", "3_third_up.sql"},
+		},
+	}
+	for i, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			files := &readCountingFS{MapFS: fstest.MapFS{}}
+			for j, name := range []string{"first", "second", "third"} {
+				fileName := fmt.Sprintf("migrations/%d_%s", j+1, name)
+				files.MapFS[fileName+"_up.sql"] = &fstest.MapFile{Data: []byte(fmt.Sprintf("CREATE TABLE lazy_%s (id integer);", name))}
+				files.MapFS[fileName+"_down.sql"] = &fstest.MapFile{Data: []byte(fmt.Sprintf("DROP TABLE lazy_%s;", name))}
+			}
+			dbConfig := migrationhandler.DBConfig{
+				Dialector:            sqlite.Open(fmt.Sprintf("file:lazy_test_%d?mode=memory&cache=shared", i)),
+				MigrationsFolderPath: "migrations",
+				MigrationsFS:         files,
+				ChecksumCheck:        tc.checksumCheck,
+			}
+			err := migrationhandler.MigrateTo(dbConfig, "2")
+			if err != nil {
+				t.Fatalf("test error: %v", err)
+			}
+			files.read = nil
+			_, err = migrationhandler.RunMigrations(dbConfig)
+			if err != nil {
+				t.Fatalf("expected: %+v, got: %+v", nil, err)
+			}
+			sort.Strings(files.read)
+			if fmt.Sprint(files.read) != fmt.Sprint(tc.expectedRead) {
+				t.Errorf("expected read: %+v, got: %+v", tc.expectedRead, files.read)
+			}
+		})
+	}
+}
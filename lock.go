@@ -0,0 +1,70 @@
+package migrationhandler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+)
+
+// lock keeps other processes from running migrations on the same database until the returned release
+// function is called. Postgres and MySQL use an advisory lock named after the migrations table, held by
+// a connection of its own, and SQLite locks the main migrations folder
+func lock(db *database, dbConfig DBConfig, options *gormigrate.Options) (func(), error) {
+	switch db.Db.Dialector.Name() {
+	case "postgres":
+		return advisoryLock(db, "SELECT 1 FROM pg_advisory_lock($1)", "SELECT pg_advisory_unlock($1)", lockKey(options))
+	case "mysql":
+		return advisoryLock(db, "SELECT COALESCE(GET_LOCK(?, -1), 0)", "SELECT RELEASE_LOCK(?)", "migrationhandler_"+options.TableName)
+	case "sqlite":
+		if dbConfig.MigrationsFS != nil || dbConfig.MigrationsArchive != "" {
+			return func() {}, nil
+		}
+		return lockFolder(mainFolder(dbConfig))
+	}
+	return func() {}, nil
+}
+
+// lockMigrations takes lock unless SkipLock is set, for everything writing to the migrations table
+func lockMigrations(db *database, dbConfig DBConfig, options *gormigrate.Options) (func(), error) {
+	if dbConfig.SkipLock {
+		return func() {}, nil
+	}
+	return lock(db, dbConfig, options)
+}
+
+// lockKey turns the name of the migrations table into the integer key of a postgres advisory lock
+func lockKey(options *gormigrate.Options) int64 {
+	hash := fnv.New64a()
+	_, _ = hash.Write([]byte(options.TableName))
+	return int64(hash.Sum64())
+}
+
+// advisoryLock runs lockSQL, which must return 1 once the lock is acquired, on a connection taken from
+// the pool, keeping it until release runs unlockSQL, as advisory locks belong to the session that acquired them
+func advisoryLock(db *database, lockSQL string, unlockSQL string, key interface{}) (func(), error) {
+	ctx := context.Background()
+	sqlDB, err := db.Db.DB()
+	if err != nil {
+		return nil, err
+	}
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var acquired int
+	err = conn.QueryRowContext(ctx, lockSQL, key).Scan(&acquired)
+	if err == nil && acquired != 1 {
+		err = errors.New("lock was not acquired")
+	}
+	if err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("could not lock migrations: %w", err)
+	}
+	return func() {
+		_, _ = conn.ExecContext(ctx, unlockSQL, key)
+		_ = conn.Close()
+	}, nil
+}
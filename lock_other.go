@@ -0,0 +1,8 @@
+//go:build !unix
+
+package migrationhandler
+
+// lockFolder does not lock anything on platforms without flock
+func lockFolder(folderPath string) (func(), error) {
+	return func() {}, nil
+}
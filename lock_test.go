@@ -0,0 +1,149 @@
+//go:build unix
+
+package migrationhandler_test
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	migrationhandler "github.com/jvfrodrigues/gorm-migration-handler"
+)
+
+func TestRunMigrationsLock(t *testing.T) {
+	tests := []struct {
+		name            string
+		skipLock        bool
+		expectedBlocked bool
+	}{
+		{
+			name:            "Test if migrations wait for the lock held by another process",
+			skipLock:        false,
+			expectedBlocked: true,
+		},
+		{
+			name:            "Test if migrations do not wait for the lock with SkipLock",
+			skipLock:        true,
+			expectedBlocked: false,
+		},
+	}
+	for i, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := tempDir(t)
+			defer func() {
+				_ = os.RemoveAll(dir)
+			}()
+			dbConfig := migrationhandler.DBConfig{
+				Dialector:            sqlite.Open("file:lock_test?mode=memory&cache=shared"),
+				MigrationsFolderPath: "./" + dir,
+				TableName:            fmt.Sprintf("migrations_lock_%v", i),
+				SkipLock:             tc.skipLock,
+			}
+			writeMigration(t, dir, "1_first_up.sql", "")
+			writeMigration(t, dir, "1_first_down.sql", "")
+			folder, err := os.Open(dir)
+			if err != nil {
+				t.Fatalf("test error: %v", err)
+			}
+			defer folder.Close()
+			err = syscall.Flock(int(folder.Fd()), syscall.LOCK_EX)
+			if err != nil {
+				t.Fatalf("test error: %v", err)
+			}
+			done := make(chan error)
+			go func() {
+				_, err := migrationhandler.RunMigrations(dbConfig)
+				done <- err
+			}()
+			blocked := false
+			select {
+			case err = <-done:
+			case <-time.After(200 * time.Millisecond):
+				blocked = true
+			}
+			_ = syscall.Flock(int(folder.Fd()), syscall.LOCK_UN)
+			if blocked {
+				err = <-done
+			}
+			if blocked != tc.expectedBlocked {
+				t.Errorf("expected run to wait for the lock: %+v, got: %+v", tc.expectedBlocked, blocked)
+			}
+			if err != nil {
+				t.Errorf("expected: %+v, got: %+v", nil, err)
+			}
+		})
+	}
+}
+
+func TestRecordsWaitForLock(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(dbConfig migrationhandler.DBConfig) error
+	}{
+		{
+			name: "Test if FakeApply waits for the lock held by another process",
+			run: func(dbConfig migrationhandler.DBConfig) error {
+				return migrationhandler.FakeApply(dbConfig, "1")
+			},
+		},
+		{
+			name: "Test if Baseline waits for the lock held by another process",
+			run: func(dbConfig migrationhandler.DBConfig) error {
+				return migrationhandler.Baseline(dbConfig, "1")
+			},
+		},
+		{
+			name: "Test if Repair waits for the lock held by another process",
+			run: func(dbConfig migrationhandler.DBConfig) error {
+				dbConfig.RepairRecords = true
+				return migrationhandler.Repair(dbConfig)
+			},
+		},
+	}
+	for i, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := tempDir(t)
+			defer func() {
+				_ = os.RemoveAll(dir)
+			}()
+			dbConfig := migrationhandler.DBConfig{
+				Dialector:            sqlite.Open(fmt.Sprintf("file:lock_records_test_%d?mode=memory&cache=shared", i)),
+				MigrationsFolderPath: "./" + dir,
+			}
+			writeMigration(t, dir, "1_first_up.sql", "CREATE TABLE lock_records_first (id integer);")
+			writeMigration(t, dir, "1_first_down.sql", "DROP TABLE lock_records_first;")
+			folder, err := os.Open(dir)
+			if err != nil {
+				t.Fatalf("test error: %v", err)
+			}
+			defer folder.Close()
+			err = syscall.Flock(int(folder.Fd()), syscall.LOCK_EX)
+			if err != nil {
+				t.Fatalf("test error: %v", err)
+			}
+			done := make(chan error)
+			go func() {
+				done <- tc.run(dbConfig)
+			}()
+			blocked := false
+			select {
+			case err = <-done:
+			case <-time.After(200 * time.Millisecond):
+				blocked = true
+			}
+			_ = syscall.Flock(int(folder.Fd()), syscall.LOCK_UN)
+			if blocked {
+				err = <-done
+			}
+			if !blocked {
+				t.Errorf("expected it to wait for the lock")
+			}
+			if err != nil {
+				t.Errorf("expected: %+v, got: %+v", nil, err)
+			}
+		})
+	}
+}
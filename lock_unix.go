@@ -0,0 +1,26 @@
+//go:build unix
+
+package migrationhandler
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// lockFolder holds an exclusive flock on the folder until the returned release function is called
+func lockFolder(folderPath string) (func(), error) {
+	folder, err := os.Open(folderPath)
+	if err != nil {
+		return nil, err
+	}
+	err = syscall.Flock(int(folder.Fd()), syscall.LOCK_EX)
+	if err != nil {
+		_ = folder.Close()
+		return nil, fmt.Errorf("could not lock migrations: %w", err)
+	}
+	return func() {
+		_ = syscall.Flock(int(folder.Fd()), syscall.LOCK_UN)
+		_ = folder.Close()
+	}, nil
+}
@@ -0,0 +1,32 @@
+package migrationhandler
+
+import "fmt"
+
+// Logger receives the messages written while creating and running migrations
+type Logger interface {
+	Info(format string, args ...interface{})
+	Warn(format string, args ...interface{})
+	Error(format string, args ...interface{})
+}
+
+// stdoutLogger is the default Logger, it prints every message to stdout
+type stdoutLogger struct{}
+
+func (stdoutLogger) Info(format string, args ...interface{}) {
+	fmt.Printf(format+"\n", args...)
+}
+
+func (stdoutLogger) Warn(format string, args ...interface{}) {
+	fmt.Printf(format+"\n", args...)
+}
+
+func (stdoutLogger) Error(format string, args ...interface{}) {
+	fmt.Printf(format+"\n", args...)
+}
+
+func getLogger(dbConfig DBConfig) Logger {
+	if dbConfig.Logger == nil {
+		return stdoutLogger{}
+	}
+	return dbConfig.Logger
+}
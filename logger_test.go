@@ -0,0 +1,116 @@
+package migrationhandler_test
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	migrationhandler "github.com/jvfrodrigues/gorm-migration-handler"
+)
+
+type testLogger struct {
+	messages []string
+}
+
+func (l *testLogger) Info(format string, args ...interface{}) {
+	l.messages = append(l.messages, "INFO "+fmt.Sprintf(format, args...))
+}
+
+func (l *testLogger) Warn(format string, args ...interface{}) {
+	l.messages = append(l.messages, "WARN "+fmt.Sprintf(format, args...))
+}
+
+func (l *testLogger) Error(format string, args ...interface{}) {
+	l.messages = append(l.messages, "ERROR "+fmt.Sprintf(format, args...))
+}
+
+func TestLogger(t *testing.T) {
+	dir := tempDir(t)
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+	logger := &testLogger{}
+	dbConfig := migrationhandler.DBConfig{
+		Dialector:            sqlite.Open("file:logger_test?mode=memory&cache=shared"),
+		MigrationsFolderPath: "./" + dir,
+		Logger:               logger,
+	}
+	_, _, err := migrationhandler.CreateMigration(dbConfig, "test")
+	if err != nil {
+		t.Fatalf("test error: %v", err)
+	}
+	_, err = migrationhandler.RunMigrations(dbConfig)
+	if err != nil {
+		t.Fatalf("test error: %v", err)
+	}
+	expected := []string{
+		"INFO No auto changes found.",
+		"INFO Migration 'test' created successfully.",
+		"INFO Migrations successful, 1 applied",
+	}
+	if fmt.Sprint(logger.messages) != fmt.Sprint(expected) {
+		t.Errorf("expected: %+v, got: %+v", expected, logger.messages)
+	}
+}
+
+func TestMustRunMigrations(t *testing.T) {
+	dir := tempDir(t)
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+	logger := &testLogger{}
+	dbConfig := migrationhandler.DBConfig{
+		Dialector:            sqlite.Open("file:must_run_test?mode=memory&cache=shared"),
+		MigrationsFolderPath: "./" + dir,
+		Logger:               logger,
+	}
+	writeMigration(t, dir, "1_first_up.sql", "CREATE TABLE must_run_first (id integer);")
+	writeMigration(t, dir, "1_first_down.sql", "DROP TABLE must_run_first;")
+	tests := []struct {
+		name            string
+		secondUp        string
+		expectedApplied int
+		expectedError   bool
+		expectedMessage string
+	}{
+		{
+			name:            "Test if the applied migrations are counted",
+			expectedApplied: 1,
+			expectedError:   false,
+			expectedMessage: "INFO Migrations successful, 1 applied",
+		},
+		{
+			name:            "Test if nothing pending is not reported as applied",
+			expectedApplied: 0,
+			expectedError:   false,
+			expectedMessage: "INFO No pending migrations, database is up to date",
+		},
+		{
+			name:            "Test if failures are logged",
+			secondUp:        "CREATE TABLE must_run_first (id integer);",
+			expectedApplied: 0,
+			expectedError:   true,
+			expectedMessage: "ERROR Migrations failed after applying 0: SQL logic error: table must_run_first already exists (1)",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			logger.messages = nil
+			if tc.secondUp != "" {
+				writeMigration(t, dir, "2_second_up.sql", tc.secondUp)
+				writeMigration(t, dir, "2_second_down.sql", "")
+			}
+			applied, err := migrationhandler.MustRunMigrations(dbConfig)
+			if (err != nil) != tc.expectedError {
+				t.Errorf("expected error: %+v, got: %+v", tc.expectedError, err)
+			}
+			if applied != tc.expectedApplied {
+				t.Errorf("expected applied: %+v, got: %+v", tc.expectedApplied, applied)
+			}
+			if len(logger.messages) == 0 || logger.messages[len(logger.messages)-1] != tc.expectedMessage {
+				t.Errorf("expected: %+v, got: %+v", tc.expectedMessage, logger.messages)
+			}
+		})
+	}
+}
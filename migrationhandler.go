@@ -3,252 +3,1632 @@
 package migrationhandler
 
 import (
-	"bufio"
+	"bytes"
 	"errors"
 	"fmt"
+	"io"
+	"io/fs"
+	"math/rand"
 	"os"
+	"path"
+	"path/filepath"
+	"reflect"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 	"text/template"
 	"time"
 
 	"github.com/go-gormigrate/gormigrate/v2"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"gorm.io/gorm/logger"
 )
 
 type templateStruct struct {
-	MigrationSQL string
+	ID            string
+	Name          string
+	CreatedAt     time.Time
+	HeaderComment string
+	MigrationSQL  string
 }
 
-const migrationTemplate string = `-- Write your SQL command here
+const migrationTemplate string = `{{.HeaderComment}}
 {{.MigrationSQL}}`
 
+// DefaultHeaderComment is the comment at the top of the files of new migrations
+const DefaultHeaderComment = "Write your SQL command here"
+
+var schemaProbeFilter = regexp.MustCompile(`(?i)^SELECT\b.*\b(sqlite_master|sqlite_schema|information_schema|pg_catalog|pg_indexes|pg_tables)\b|^SELECT\s+(DATABASE|CURRENT_DATABASE|CURRENT_SCHEMA|SCHEMA|VERSION)\s*\(\)`)
+
+// dialectProbeFilters match the queries gorm runs on each dialect to inspect the current schema
+var dialectProbeFilters = map[string]*regexp.Regexp{
+	"sqlite":   regexp.MustCompile(`(?i)^SELECT\b.*\b(sqlite_master|sqlite_schema)\b|^SELECT\s+SQLITE_VERSION\s*\(\)`),
+	"mysql":    regexp.MustCompile(`(?i)^SELECT\b.*\binformation_schema\b|^SELECT\s+(DATABASE|SCHEMA|VERSION)\s*\(\)|^SHOW\b`),
+	"postgres": regexp.MustCompile(`(?i)^SELECT\b.*\b(information_schema|pg_catalog|pg_indexes|pg_tables|pg_class|pg_description)\b|^SELECT\s+(CURRENT_DATABASE|CURRENT_SCHEMA|VERSION)\s*\(\)`),
+}
+
+var createTableFilter = regexp.MustCompile("(?i)^CREATE TABLE\\s+(?:IF NOT EXISTS\\s+)?([`\"\\[]?[\\w.]+[`\"\\]]?)")
+
+// addColumnFilter matches the ALTER TABLE statements adding a column, with or without the COLUMN keyword
+var addColumnFilter = regexp.MustCompile("(?i)^ALTER TABLE\\s+([`\"\\[]?[\\w.]+[`\"\\]]?)\\s+ADD\\s+(?:COLUMN\\s+)?([`\"\\[]?\\w+[`\"\\]]?)")
+
+// addKeywords follow ADD in ALTER TABLE statements that add something other than a column
+var addKeywords = map[string]bool{
+	"CONSTRAINT": true, "INDEX": true, "KEY": true, "PRIMARY": true, "FOREIGN": true,
+	"UNIQUE": true, "CHECK": true, "FULLTEXT": true, "SPATIAL": true,
+}
+
 type database struct {
 	Db *gorm.DB
 }
 
-// DBConfig gets the gorm dialector to connect to the database, the models in the project and your migrations folder path
+// DBConfig gets the gorm dialector to connect to the database, the models in the project and your migrations folder path.
+// When MigrationsFS is set migrations are run from it, using MigrationsFolderPath as the folder inside of it
 type DBConfig struct {
-	Dialector            gorm.Dialector
-	Models               []interface{}
+	Dialector gorm.Dialector
+	Models    []interface{}
+	// ModelsProvider is called by CreateMigration to get the models registered at runtime, which are merged
+	// after Models leaving out the types already there
+	ModelsProvider       func() []interface{}
 	MigrationsFolderPath string
+	MigrationsFS         fs.FS
+	// MigrationsArchive is the path of a .zip, .tar, .tar.gz or .tgz archive to read migrations from instead of
+	// MigrationsFS, for migrations shipped as an artifact of their own. MigrationsFolderPath is the folder inside of it
+	MigrationsArchive string
+	// MigrationsFolderPaths are more folders to load migrations from, like the ones shipped by a library, merged
+	// with the ones of MigrationsFolderPath sorted by ID. New migrations are still created in MigrationsFolderPath
+	MigrationsFolderPaths []string
+	// OrderFile overrides DefaultOrderFile, the name of the manifest listing migration IDs in the order they are
+	// applied, kept in MigrationsFolderPath or the first of MigrationsFolderPaths when it is not set. When it
+	// exists it is used instead of sorting migrations by ID
+	OrderFile string
+	// DB is an already open connection used instead of opening a new one from Dialector,
+	// sharing the pool and its settings with the rest of the application
+	DB *gorm.DB
+	// ConnInitSQL are statements run on the connection opened from Dialector before anything else, like
+	// "PRAGMA foreign_keys = ON" or MySQL session variables. Every statement then runs on that same connection so
+	// the settings apply to all of them. It is not used with DB, whose connections are set up by the application
+	ConnInitSQL []string
+	// ConnectRetries is how many more times opening Dialector is tried when it fails, like while the database is
+	// still starting, defaults to 0 to fail at once. It is not used with DB
+	ConnectRetries int
+	// ConnectBackoff is the wait before the first retry, doubled after every one, defaults to DefaultConnectBackoff
+	ConnectBackoff time.Duration
+	// TableName overrides the name of the table used to keep track of applied migrations
+	TableName string
+	// TableSchema puts the migrations table in a schema of its own, like "migrations" for "migrations.migrations",
+	// created when it does not exist. It is left out when the table name is already qualified with a schema
+	TableSchema string
+	// IDFormat overrides the time layout used to generate new migration IDs, defaults to DefaultIDFormat
+	IDFormat string
+	// NowFunc overrides time.Now as the clock giving the ID and creation time of new migrations,
+	// so tests can fix it to know the names of the files created
+	NowFunc func() time.Time
+	// Logger receives the messages of the package, defaults to printing them to stdout
+	Logger Logger
+	// LogLevel sets what gorm logs while running migrations, like every statement and its timing on
+	// logger.Info, defaults to logger.Silent. When DB or GormConfig has a logger it is used at this level
+	LogLevel logger.LogLevel
+	// GormConfig is the base of the config used to open Dialector, so settings like NamingStrategy match
+	// the ones of the application. SkipDefaultTransaction is always set and its Logger is set to LogLevel
+	GormConfig *gorm.Config
+	// AllowIncompletePairs loads migrations missing their down file instead of failing,
+	// a down file without its up file is always an error
+	AllowIncompletePairs bool
+	// DryRun makes RunMigrations log the SQL of the pending migrations without executing it
+	DryRun bool
+	// AutoSQLFilter reports if a statement generated by the auto migration should be written
+	// to the migration file, defaults to DialectAutoSQLFilter for the dialect of the database
+	AutoSQLFilter func(statement string) bool
+	// Options are passed to gormigrate when running migrations, defaults to gormigrate.DefaultOptions.
+	// TableName takes precedence over the table name set here and UseTransaction is the same as TransactionPerRun
+	Options *gormigrate.Options
+	// Transaction sets how migrations are wrapped in transactions, defaults to TransactionPerMigration
+	Transaction TransactionMode
+	// ChecksumCheck sets how an applied migration whose up file changed since it was applied is reported
+	// when running migrations, defaults to CheckError
+	ChecksumCheck CheckMode
+	// OutOfOrderCheck sets how a pending migration with an ID lower than an applied one is reported when running
+	// migrations, like one merged after newer ones were deployed, defaults to CheckError
+	OutOfOrderCheck CheckMode
+	// Template overrides the text/template used to write the up and down files of new migrations.
+	// It receives .ID, .Name, .CreatedAt (a time.Time), .HeaderComment and .MigrationSQL, the up or down SQL of the file
+	Template string
+	// HeaderComment overrides DefaultHeaderComment, the text written as a SQL comment at the top of new files,
+	// every line not starting with "--" is prefixed with it
+	HeaderComment string
+	// Output makes CreateMigration write the up and down files to it instead of the migrations folder, each one
+	// after a "-- <file name>" line, like to print them to stdout. The paths returned are where they would be written
+	Output io.Writer
+	// FileMode sets the permissions of the files of new migrations, like 0o664 or 0o444, ignoring the umask.
+	// Defaults to 0666 less the umask
+	FileMode os.FileMode
+	// CreateMigrationFolder creates MigrationsFolderPath when creating a migration if it does not exist yet,
+	// making the first migration of a project work without creating the folder by hand
+	CreateMigrationFolder bool
+	// UpSuffix and DownSuffix override the end of the names of up and down files, like ".up.sql" and ".down.sql",
+	// defaults to DefaultUpSuffix and DefaultDownSuffix
+	UpSuffix   string
+	DownSuffix string
+	// SkipLock runs migrations without first locking them against other processes running them at the same time,
+	// the lock is also taken by Baseline, FakeApply and Repair with RepairRecords while they record migrations.
+	// The lock keeps a connection of its own on Postgres and MySQL, so pools limited to a single connection need it
+	SkipLock bool
+	// DestructiveCheck sets how CreateMigration reports auto generated statements matching DestructivePatterns,
+	// by default it returns an error so data losing statements are only written when allowed
+	DestructiveCheck CheckMode
+	// DestructivePatterns overrides DefaultDestructivePatterns
+	DestructivePatterns []*regexp.Regexp
+	// SQLTransform changes the SQL of up and down files right before it runs, like prepending a
+	// "SET search_path TO tenant;" or replacing placeholders. Checksums are taken from the files as written,
+	// and directives like "-- delimiter" are read from the transformed SQL so they must stay at its top
+	SQLTransform func(sql string) string
+	// GoMigrations are migrations written in Go, merged with the ones of the migrations folder sorted by ID
+	GoMigrations []GoMigration
+	// SeedSchemaSQL is run before the first migration of a database, when the migrations table does not exist yet,
+	// to create a base schema like the legacy tables of production for tests. It runs outside the migrations table
+	// so it is not recorded, checksummed or rolled back, and does not run again once any migration was run
+	SeedSchemaSQL string
+	// ExpandEnv replaces the ${VAR} references of up and down files with the value of the environment variable
+	// when they are read, like tablespace names changing between environments. A variable that is not set is an
+	// error. Checksums are taken from the files as written, so changing the value of a variable does not change them
+	ExpandEnv bool
+	// OnEvent is called when a migration starts, is applied, skipped, fails or is rolled back, see Event
+	OnEvent func(Event)
+	// BeforeMigrate and AfterMigrate run around the migrations applied by RunMigrations and MigrateTo
+	BeforeMigrate Hook
+	AfterMigrate  Hook
+	// BeforeRollback and AfterRollback run around the migrations rolled back by RollbackMigration, RollbackTo
+	// and ResetMigrations
+	BeforeRollback Hook
+	AfterRollback  Hook
+	// Recursive loads migrations from every subfolder of the migrations folder too, still sorted by ID across all of them
+	Recursive bool
+	// SchemaDumpPath is the file DumpSchema writes the schema to, like "./schema.sql"
+	SchemaDumpPath string
+	// UpPattern and DownPattern override how up and down files are found when loading migrations, like
+	// `^V(?P<id>\d+)__(?P<name>.+)_up\.sql$`. The ID of the migration is read from the id group and its name
+	// from the optional name group. New migrations are still named with UpSuffix and DownSuffix
+	UpPattern   string
+	DownPattern string
+	// SkipEmpty makes CreateMigration return ErrNoChanges without writing any file when the auto migration
+	// finds no changes
+	SkipEmpty bool
+	// SkipAutoDiff makes CreateMigration write empty up and down files without connecting to the database,
+	// for hand written migrations and environments without access to it
+	SkipAutoDiff bool
+	// RepairRecords makes Repair change the migrations table to match the folder instead of only reporting
+	// the differences, removing records without a file and recording migrations that look applied
+	RepairRecords bool
 }
 
+// DefaultIDFormat is the time layout used for migration IDs, it is lexically sortable and has
+// microsecond resolution so migrations created in quick succession do not collide
+const DefaultIDFormat = "20060102150405.000000"
+
+// Default suffixes of the up and down files of a migration
+const (
+	DefaultUpSuffix   = "_up.sql"
+	DefaultDownSuffix = "_down.sql"
+)
+
 type migration struct {
 	id           string
 	name         string
+	createdAt    time.Time
 	migrationSQL string
 	rollbackSQL  string
+	upFile       string
+	downFile     string
+	upDialect    bool
+	downDialect  bool
+	// irreversible is set by an "-- irreversible" line on the header of the down file
+	irreversible bool
+	// upNoTransaction and downNoTransaction are set by a "-- no-transaction" line on the header of their file
+	upNoTransaction   bool
+	downNoTransaction bool
+	// squashes is set by a "-- squashes: <ID>" line on the header of the up file of a baseline written by Squash
+	squashes string
+	// guard is set by a "-- guard: <query>" line on the header of the up file, a query returning a boolean which
+	// when false skips the SQL of the migration while still recording it as applied
+	guard string
+	// squashed lists the migrations left out of the run as the baseline replaces them, and markOnly is set
+	// when the database already has any of them applied, so the baseline is marked as applied without running it
+	squashed []string
+	markOnly bool
+	// upSource and downSource read the up and down files, set until load reads them into migrationSQL
+	// and rollbackSQL
+	upSource   func() (string, error)
+	downSource func() (string, error)
+	// expandEnv is set by ExpandEnv to replace the environment variables of the files once they are read, and
+	// upChecksum is the checksum of the up file as written, before they are replaced
+	expandEnv  bool
+	upChecksum string
+	// goMigrate and goRollback are set on migrations written in Go, which have no files
+	goMigrate  func(tx *gorm.DB) error
+	goRollback func(tx *gorm.DB) error
 }
 
-// CreateMigration requires the dbConfig and your migration folder path and the name of the migration you want to create
-func CreateMigration(databaseConfig DBConfig, migrationName string) error {
-	newMigration := migration{
-		id:   fmt.Sprint(time.Now().Unix()),
-		name: migrationName,
+// migrationRecord keeps extra info about applied migrations, as gormigrate's table only stores their IDs
+type migrationRecord struct {
+	ID        string `gorm:"primaryKey;size:255"`
+	AppliedAt time.Time
+	Checksum  string `gorm:"size:64"`
+}
+
+// CreateMigration requires the dbConfig and your migration folder path and the name of the migration you want to create,
+// returning the paths of the up and down files it wrote
+func CreateMigration(databaseConfig DBConfig, migrationName string) (string, string, error) {
+	err := validateMigrationName(databaseConfig, migrationName)
+	if err != nil {
+		return "", "", err
+	}
+	newMigration := newMigration(databaseConfig, migrationName)
+	if databaseConfig.SkipAutoDiff {
+		return writeMigration(databaseConfig, newMigration)
 	}
 	db, err := newDatabase(databaseConfig)
 	if err != nil {
-		fmt.Println("Database connection failed skipping auto migration")
+		getLogger(databaseConfig).Warn("Database connection failed skipping auto migration")
 	} else {
-		migrationSQL := getChangesAuto(db, databaseConfig.Models)
+		migrationSQL, rollbackSQL, err := getChangesAuto(db, databaseConfig)
+		if err != nil {
+			return "", "", err
+		}
+		err = checkDestructive(databaseConfig, migrationSQL)
+		if err != nil {
+			return "", "", err
+		}
 		if migrationSQL == "" {
-			fmt.Println("No auto changes found.")
+			if databaseConfig.SkipEmpty {
+				return "", "", ErrNoChanges
+			}
+			getLogger(databaseConfig).Info("No auto changes found.")
 		}
 		newMigration.migrationSQL = migrationSQL
+		newMigration.rollbackSQL = rollbackSQL
+	}
+	return writeMigration(databaseConfig, newMigration)
+}
+
+// PreviewAutoMigration requires the dbConfig and returns the up SQL CreateMigration would write for the
+// current models, without creating any files
+func PreviewAutoMigration(databaseConfig DBConfig) (string, error) {
+	db, err := newDatabase(databaseConfig)
+	if err != nil {
+		return "", fmt.Errorf("%w, can not preview migration: %w", ErrConnectionFailed, err)
+	}
+	migrationSQL, _, err := getChangesAuto(db, databaseConfig)
+	return migrationSQL, err
+}
+
+// CreateMigrationWithSQL requires the dbConfig and the name of the migration you want to create with the SQL
+// to write on its up and down files, no auto migration is done so the database is not accessed
+func CreateMigrationWithSQL(databaseConfig DBConfig, migrationName string, upSQL string, downSQL string) error {
+	err := validateMigrationName(databaseConfig, migrationName)
+	if err != nil {
+		return err
+	}
+	newMigration := newMigration(databaseConfig, migrationName)
+	newMigration.migrationSQL = upSQL
+	newMigration.rollbackSQL = downSQL
+	_, _, err = writeMigration(databaseConfig, newMigration)
+	return err
+}
+
+// writeMigration writes the files of a new migration, returning the paths of its up and down files
+func writeMigration(databaseConfig DBConfig, newMigration migration) (string, string, error) {
+	if databaseConfig.CreateMigrationFolder && databaseConfig.Output == nil {
+		err := os.MkdirAll(databaseConfig.MigrationsFolderPath, 0o755)
+		if err != nil {
+			return "", "", err
+		}
+	}
+	upPath, downPath, err := generateFiles(newMigration, databaseConfig)
+	if err != nil {
+		return "", "", err
+	}
+	getLogger(databaseConfig).Info("Migration '%s' created successfully.", newMigration.name)
+	return upPath, downPath, nil
+}
+
+// AppliedMigration identifies a migration that was applied to the database
+type AppliedMigration struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	// Duration is how long it took to apply, zero for migrations skipped or listed by a dry run
+	Duration time.Duration `json:"duration_ns"`
+}
+
+// idFormat returns IDFormat, defaulting to DefaultIDFormat
+func idFormat(dbConfig DBConfig) string {
+	if dbConfig.IDFormat == "" {
+		return DefaultIDFormat
+	}
+	return dbConfig.IDFormat
+}
+
+func newMigration(dbConfig DBConfig, migrationName string) migration {
+	now := dbConfig.NowFunc
+	if now == nil {
+		now = time.Now
+	}
+	createdAt := now().UTC()
+	return migration{
+		id:        createdAt.Format(idFormat(dbConfig)),
+		name:      migrationName,
+		createdAt: createdAt,
+	}
+}
+
+// RunMigrations gets DB info and gets all migrations from given folder to run on the database,
+// returning the migrations applied by this run even when a later one fails.
+// On DryRun it returns the migrations that would be applied without changing the database
+func RunMigrations(dbConfig DBConfig) ([]AppliedMigration, error) {
+	summary, err := RunMigrationsWithSummary(dbConfig)
+	return summary.Applied, err
+}
+
+// RunSummary tells the migrations applied by a run apart from the ones skipped as they were already applied
+type RunSummary struct {
+	Applied []AppliedMigration `json:"applied"`
+	Skipped []AppliedMigration `json:"skipped"`
+}
+
+// RunMigrationsWithSummary runs the migrations like RunMigrations, also returning the migrations that were
+// skipped as they were already applied before the run
+func RunMigrationsWithSummary(dbConfig DBConfig) (RunSummary, error) {
+	manager, err := setupManager(dbConfig)
+	if err != nil {
+		return RunSummary{}, err
+	}
+	defer manager.release()
+	err = manager.verifyChecksums(dbConfig.ChecksumCheck)
+	if err != nil {
+		return RunSummary{}, err
+	}
+	err = manager.verifyOrder(dbConfig.OutOfOrderCheck)
+	if err != nil {
+		return RunSummary{}, err
+	}
+	skipped, err := manager.alreadyApplied()
+	if err != nil {
+		return RunSummary{}, err
+	}
+	for _, migration := range skipped {
+		manager.emit(Event{Type: EventSkipped, ID: migration.ID, Name: migration.Name})
+	}
+	err = manager.seedSchema(dbConfig)
+	if err != nil {
+		return RunSummary{}, err
+	}
+	if dbConfig.DryRun {
+		applied, err := manager.dryRun()
+		return RunSummary{Applied: applied, Skipped: skipped}, err
+	}
+	err = withHooks(manager.db, dbConfig.BeforeMigrate, dbConfig.AfterMigrate, manager.Migrate)
+	if err != nil {
+		if manager.options.UseTransaction {
+			// the whole run was rolled back so nothing was applied
+			return RunSummary{Applied: []AppliedMigration{}, Skipped: skipped}, err
+		}
+		return RunSummary{Applied: manager.applied, Skipped: skipped}, err
+	}
+	if len(skipped) > 0 {
+		names := make([]string, 0, len(skipped))
+		for _, migration := range skipped {
+			names = append(names, migration.ID+"_"+migration.Name)
+		}
+		getLogger(dbConfig).Info("Skipped %d already applied: %s", len(skipped), strings.Join(names, ", "))
+	}
+	if len(manager.applied) == 0 {
+		getLogger(dbConfig).Info("No pending migrations, database is up to date")
+	} else {
+		getLogger(dbConfig).Info("Migrations successful, %d applied", len(manager.applied))
+	}
+	return RunSummary{Applied: manager.applied, Skipped: skipped}, nil
+}
+
+// MustRunMigrations runs RunMigrations for deployment scripts, logging its error before returning it
+// along with how many migrations were applied, so a failed run can end with os.Exit(1)
+func MustRunMigrations(dbConfig DBConfig) (int, error) {
+	applied, err := RunMigrations(dbConfig)
+	if err != nil {
+		getLogger(dbConfig).Error("Migrations failed after applying %d: %v", len(applied), err)
+	}
+	return len(applied), err
+}
+
+// MigrateTo gets DB info and gets all migrations from given folder to run on the database
+// every pending migration up to and including the given migration ID
+func MigrateTo(dbConfig DBConfig, migrationID string) error {
+	manager, err := setupManager(dbConfig)
+	if err != nil {
+		return err
+	}
+	defer manager.release()
+	if !manager.hasMigration(migrationID) {
+		return fmt.Errorf("%w: %s", ErrMigrationNotFound, migrationID)
+	}
+	err = manager.verifyChecksums(dbConfig.ChecksumCheck)
+	if err != nil {
+		return err
+	}
+	err = manager.verifyOrder(dbConfig.OutOfOrderCheck)
+	if err != nil {
+		return err
+	}
+	err = manager.seedSchema(dbConfig)
+	if err != nil {
+		return err
 	}
-	err = generateFiles(newMigration, databaseConfig.MigrationsFolderPath)
+	err = withHooks(manager.db, dbConfig.BeforeMigrate, dbConfig.AfterMigrate, func() error {
+		return manager.MigrateTo(migrationID)
+	})
 	if err != nil {
 		return err
 	}
-	fmt.Printf("Migration '%s' created successfully.\n", newMigration.name)
+	getLogger(dbConfig).Info("Migrations successful")
 	return nil
 }
 
-// RunMigrations gets DB info and gets all migrations from given folder to run on the database
-func RunMigrations(dbConfig DBConfig) error {
+// RunMigrationsRange gets DB info and gets all migrations from given folder to run on the database every pending
+// migration with an ID between fromID and toID, both included. It errors when a migration before fromID is
+// still pending, as it would be skipped
+func RunMigrationsRange(dbConfig DBConfig, fromID string, toID string) ([]AppliedMigration, error) {
+	manager, err := setupManager(dbConfig)
+	if err != nil {
+		return nil, err
+	}
+	defer manager.release()
+	for _, migrationID := range []string{fromID, toID} {
+		if !manager.hasMigration(migrationID) {
+			return nil, fmt.Errorf("%w: %s", ErrMigrationNotFound, migrationID)
+		}
+	}
+	if migrationIndex(manager.migrations, fromID) > migrationIndex(manager.migrations, toID) {
+		return nil, fmt.Errorf("invalid range, migration %s comes after migration %s", fromID, toID)
+	}
+	applied, err := getAppliedMigrations(manager.db, manager.options)
+	if err != nil {
+		return nil, err
+	}
+	for _, migration := range manager.migrations[:migrationIndex(manager.migrations, fromID)] {
+		if _, ok := applied[migration.id]; !ok {
+			return nil, fmt.Errorf("migration %s_%s is pending before migration %s, apply it first", migration.id, migration.name, fromID)
+		}
+	}
+	err = manager.verifyChecksums(dbConfig.ChecksumCheck)
+	if err != nil {
+		return nil, err
+	}
+	err = manager.verifyOrder(dbConfig.OutOfOrderCheck)
+	if err != nil {
+		return nil, err
+	}
+	err = manager.seedSchema(dbConfig)
+	if err != nil {
+		return nil, err
+	}
+	// with nothing pending before fromID, migrating up to toID applies exactly the pending migrations of the range
+	err = withHooks(manager.db, dbConfig.BeforeMigrate, dbConfig.AfterMigrate, func() error {
+		return manager.MigrateTo(toID)
+	})
+	if err != nil {
+		if manager.options.UseTransaction {
+			return []AppliedMigration{}, err
+		}
+		return manager.applied, err
+	}
+	getLogger(dbConfig).Info("Migrations successful, %d applied", len(manager.applied))
+	return manager.applied, nil
+}
+
+// RunOne gets DB info and gets migration folder to run only the migration with the given ID, without running or
+// requiring the pending migrations before it. Use it with care for hotfixes: it leaves gaps that the next run
+// fills in out of order, so the skipped migrations must not depend on it and OutOfOrderCheck must allow them
+func RunOne(dbConfig DBConfig, migrationID string) error {
 	manager, err := setupManager(dbConfig)
 	if err != nil {
 		return err
 	}
-	err = manager.Migrate()
+	defer manager.release()
+	var found *migration
+	for i := range manager.migrations {
+		if manager.migrations[i].id == migrationID {
+			found = &manager.migrations[i]
+		}
+	}
+	if found == nil {
+		return fmt.Errorf("%w: %s", ErrMigrationNotFound, migrationID)
+	}
+	applied, err := getAppliedMigrations(manager.db, manager.options)
+	if err != nil {
+		return err
+	}
+	if _, ok := applied[migrationID]; ok {
+		return fmt.Errorf("migration %s_%s is already applied", found.id, found.name)
+	}
+	if dbConfig.DryRun {
+		err = found.loadUp()
+		if err != nil {
+			return err
+		}
+		manager.logger.Info("Migration '%s_%s' would run:\n%s", found.id, found.name, found.describeUp(manager.transformSQL))
+		return nil
+	}
+	err = manager.verifyChecksums(dbConfig.ChecksumCheck)
+	if err != nil {
+		return err
+	}
+	err = manager.seedSchema(dbConfig)
+	if err != nil {
+		return err
+	}
+	// a gormigrate with only this migration runs it without looking at the ones before it
+	options := *manager.options
+	options.ValidateUnknownMigrations = false
+	single := gormigrate.New(manager.db.Db, &options, []*gormigrate.Migration{manager.setupMigration(*found)})
+	err = withHooks(manager.db, dbConfig.BeforeMigrate, dbConfig.AfterMigrate, single.Migrate)
+	if err != nil {
+		return err
+	}
+	getLogger(dbConfig).Info("Migration '%s_%s' applied", found.id, found.name)
+	return nil
+}
+
+// RollbackMigration gets DB info and gets migration folder to find and rollback the latest migration,
+// returning which migration was rolled back
+func RollbackMigration(dbConfig DBConfig) (MigrationInfo, error) {
+	manager, err := setupManager(dbConfig)
+	if err != nil {
+		return MigrationInfo{}, err
+	}
+	defer manager.release()
+	err = withHooks(manager.db, dbConfig.BeforeRollback, dbConfig.AfterRollback, manager.RollbackLast)
+	if err != nil {
+		return MigrationInfo{}, err
+	}
+	rolledBack := manager.rolledBack[len(manager.rolledBack)-1]
+	getLogger(dbConfig).Info("Rollback successful, rolled back %s_%s", rolledBack.ID, rolledBack.Name)
+	return MigrationInfo{ID: rolledBack.ID, Name: rolledBack.Name}, nil
+}
+
+// RollbackTo gets DB info and gets migration folder to rollback every migration after the given migration ID,
+// from the latest to the earliest. The given migration itself is not rolled back
+func RollbackTo(dbConfig DBConfig, migrationID string) error {
+	manager, err := setupManager(dbConfig)
 	if err != nil {
 		return err
 	}
-	fmt.Println("Migrations successful")
+	defer manager.release()
+	if !manager.hasMigration(migrationID) {
+		return fmt.Errorf("%w: %s", ErrMigrationNotFound, migrationID)
+	}
+	err = withHooks(manager.db, dbConfig.BeforeRollback, dbConfig.AfterRollback, func() error {
+		return manager.RollbackTo(migrationID)
+	})
+	if err != nil {
+		return err
+	}
+	getLogger(dbConfig).Info("Rollback successful")
 	return nil
 }
 
-// RollbackMigration gets DB info and gets migration folder to find and rollback the latest migration
-func RollbackMigration(dbConfig DBConfig) error {
+// RollbackN gets DB info and gets migration folder to rollback the last n applied migrations, from the latest
+// to the earliest. Nothing is rolled back when fewer than n are applied or one of them is missing its down file
+func RollbackN(dbConfig DBConfig, n int) error {
+	if n <= 0 {
+		return fmt.Errorf("invalid number of migrations to rollback %d", n)
+	}
 	manager, err := setupManager(dbConfig)
 	if err != nil {
 		return err
 	}
-	err = manager.RollbackLast()
+	defer manager.release()
+	applied, err := manager.alreadyApplied()
+	if err != nil {
+		return err
+	}
+	if len(applied) < n {
+		return fmt.Errorf("can not rollback %d migrations, only %d are applied", n, len(applied))
+	}
+	toRollback := make(map[string]bool)
+	for _, migration := range applied[len(applied)-n:] {
+		toRollback[migration.ID] = true
+	}
+	for _, migration := range manager.migrations {
+		if !toRollback[migration.id] {
+			continue
+		}
+		if err := migration.checkRollback(fmt.Sprintf("rollback %d migrations", n)); err != nil {
+			return err
+		}
+	}
+	err = withHooks(manager.db, dbConfig.BeforeRollback, dbConfig.AfterRollback, func() error {
+		for i := 0; i < n; i++ {
+			err := manager.RollbackLast()
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
 	if err != nil {
 		return err
 	}
-	fmt.Println("Rollback successful")
+	getLogger(dbConfig).Info("Rollback successful, %d rolled back", n)
 	return nil
 }
 
-func setupManager(dbConfig DBConfig) (*gormigrate.Gormigrate, error) {
+// ResetMigrations gets DB info and gets migration folder to rollback every applied migration, from the latest
+// to the earliest. Nothing is rolled back when an applied migration is missing its down file or is irreversible
+func ResetMigrations(dbConfig DBConfig) error {
+	manager, err := setupManager(dbConfig)
+	if err != nil {
+		return err
+	}
+	defer manager.release()
+	applied, err := getAppliedMigrations(manager.db, manager.options)
+	if err != nil {
+		return err
+	}
+	for _, migration := range manager.migrations {
+		if _, ok := applied[migration.id]; !ok {
+			continue
+		}
+		if err := migration.checkRollback("reset migrations"); err != nil {
+			return err
+		}
+	}
+	err = withHooks(manager.db, dbConfig.BeforeRollback, dbConfig.AfterRollback, func() error {
+		for range applied {
+			err := manager.RollbackLast()
+			if errors.Is(err, gormigrate.ErrNoRunMigration) {
+				// rolling back a baseline also rolls back the migrations it squashed
+				break
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	getLogger(dbConfig).Info("Reset successful")
+	return nil
+}
+
+// migrationManager wraps gormigrate keeping track of what happened during its run
+type migrationManager struct {
+	*gormigrate.Gormigrate
+	db         *database
+	logger     Logger
+	options    *gormigrate.Options
+	migrations []migration
+	applied    []AppliedMigration
+	rolledBack []AppliedMigration
+	release    func()
+	// transactionalDDL is unset for dialects committing DDL statements implicitly, whose migrations
+	// are not wrapped in a transaction of their own
+	transactionalDDL bool
+	transformSQL     func(sql string) string
+	onEvent          func(Event)
+}
+
+func setupManager(dbConfig DBConfig) (*migrationManager, error) {
 	db, err := newDatabase(dbConfig)
 	if err != nil {
-		return nil, errors.New("connection to database failed, can not run migrations")
+		return nil, fmt.Errorf("%w, can not run migrations: %w", ErrConnectionFailed, err)
 	}
-	migrations, err := getMigrations(dbConfig.MigrationsFolderPath)
+	migrations, err := getMigrations(dbConfig)
 	if err != nil {
 		return nil, err
 	}
 	if len(migrations) <= 0 {
-		return nil, errors.New("no migrations to run")
+		return nil, fmt.Errorf("%w to run", ErrNoMigrations)
+	}
+	options := managerOptions(dbConfig)
+	manager := &migrationManager{
+		db:         db,
+		logger:     getLogger(dbConfig),
+		options:    options,
+		migrations: migrations,
+		applied:    make([]AppliedMigration, 0),
+		release:    func() {},
+	}
+	manager.transformSQL = getSQLTransform(dbConfig)
+	manager.onEvent = dbConfig.OnEvent
+	manager.transactionalDDL = hasTransactionalDDL(db.Db.Dialector.Name())
+	if !manager.transactionalDDL && !options.UseTransaction && !dbConfig.DryRun {
+		manager.logger.Info("%s commits DDL statements implicitly, migrations run without a transaction so a failed one can leave partial changes", db.Db.Dialector.Name())
+	}
+	err = manager.resolveBaselines()
+	if err != nil {
+		return nil, err
+	}
+	if !dbConfig.DryRun {
+		manager.release, err = lockMigrations(db, dbConfig, options)
+		if err != nil {
+			return nil, err
+		}
+		err = createTableSchema(db.Db, options)
+		if err == nil {
+			err = db.Db.Table(recordsTableName(options)).AutoMigrate(&migrationRecord{})
+		}
+		if err != nil {
+			manager.release()
+			return nil, err
+		}
 	}
 	gormMigrations := make([]*gormigrate.Migration, 0)
+	for _, migration := range manager.migrations {
+		gormMigrations = append(gormMigrations, manager.setupMigration(migration))
+	}
+	manager.Gormigrate = gormigrate.New(db.Db, options, gormMigrations)
+	return manager, nil
+}
+
+// dryRun logs the SQL of every pending migration in order without touching the database
+func (m *migrationManager) dryRun() ([]AppliedMigration, error) {
+	applied, err := getAppliedMigrations(m.db, m.options)
+	if err != nil {
+		return nil, err
+	}
+	for _, migration := range m.migrations {
+		if _, ok := applied[migration.id]; ok {
+			continue
+		}
+		err = migration.loadUp()
+		if err != nil {
+			return nil, err
+		}
+		m.logger.Info("Migration '%s_%s' would run:\n%s", migration.id, migration.name, migration.describeUp(m.transformSQL))
+		m.applied = append(m.applied, AppliedMigration{ID: migration.id, Name: migration.name})
+	}
+	m.logger.Info("Dry run successful, no changes were made")
+	return m.applied, nil
+}
+
+// verifyOrder reports the pending migrations with an ID lower than the last applied one, which run after
+// migrations that were written later than them
+func (m *migrationManager) verifyOrder(mode CheckMode) error {
+	if mode == CheckIgnore {
+		return nil
+	}
+	applied, err := getAppliedMigrations(m.db, m.options)
+	if err != nil {
+		return err
+	}
+	last := -1
+	for i, migration := range m.migrations {
+		if _, ok := applied[migration.id]; ok {
+			last = i
+		}
+	}
+	for _, migration := range m.migrations[:max(last, 0)] {
+		if _, ok := applied[migration.id]; ok {
+			continue
+		}
+		err = mode.report(m.logger, fmt.Errorf("out-of-order migration %s_%s detected, migration %s is already applied", migration.id, migration.name, m.migrations[last].id))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// alreadyApplied lists the migrations of the folder that are already applied, in order
+func (m *migrationManager) alreadyApplied() ([]AppliedMigration, error) {
+	applied, err := getAppliedMigrations(m.db, m.options)
+	if err != nil {
+		return nil, err
+	}
+	skipped := make([]AppliedMigration, 0)
+	for _, migration := range m.migrations {
+		if _, ok := applied[migration.id]; ok {
+			skipped = append(skipped, AppliedMigration{ID: migration.id, Name: migration.name})
+		}
+	}
+	return skipped, nil
+}
+
+// sortMigrations sorts the migrations read from their map by ID, the order they are applied in, so the last
+// one is the first gormigrate rolls back. Equal IDs are sorted by name to stay deterministic until validateIDs
+// reports them
+func sortMigrations(migrations map[string]migration) []migration {
+	sorted := make([]migration, 0, len(migrations))
 	for _, migration := range migrations {
-		gormMigrations = append(gormMigrations, setupMigration(migration))
+		sorted = append(sorted, migration)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].id == sorted[j].id {
+			return sorted[i].name < sorted[j].name
+		}
+		return sorted[i].id < sorted[j].id
+	})
+	return sorted
+}
+
+func (m *migrationManager) hasMigration(migrationID string) bool {
+	for _, migration := range m.migrations {
+		if migration.id == migrationID {
+			return true
+		}
 	}
-	gm := gormigrate.New(db.Db, gormigrate.DefaultOptions, gormMigrations)
-	return gm, nil
+	return false
 }
 
-func setupMigration(migration migration) *gormigrate.Migration {
+func (m *migrationManager) setupMigration(migration migration) *gormigrate.Migration {
 	return &gormigrate.Migration{
 		ID: migration.id,
-		Migrate: func(db *gorm.DB) error {
-			tx := db.Begin()
-			defer tx.Rollback()
-			err := tx.Exec(migration.migrationSQL).Error
+		Migrate: func(db *gorm.DB) (err error) {
+			start := time.Now()
+			m.emit(Event{Type: EventStarted, ID: migration.id, Name: migration.name})
+			defer func() {
+				m.emitDone(migration, false, start, err)
+			}()
+			err = migration.loadUp()
 			if err != nil {
 				return err
 			}
-			return tx.Commit().Error
+			err = m.transaction(db, migration.upNoTransaction, func(tx *gorm.DB) error {
+				run := !migration.markOnly
+				if run {
+					passes, err := m.guardPasses(tx, migration)
+					if err != nil {
+						return err
+					}
+					if !passes {
+						m.logger.Info("Migration '%s_%s' skipped as its guard is false, recording it as applied", migration.id, migration.name)
+					}
+					run = passes
+				}
+				if run {
+					err := migration.runUp(tx, m.transformSQL)
+					if err != nil {
+						return err
+					}
+				}
+				for _, squashedID := range migration.squashed {
+					err := tx.Table(m.options.TableName).Create(map[string]interface{}{m.options.IDColumnName: squashedID}).Error
+					if err != nil {
+						return err
+					}
+				}
+				record := migrationRecord{ID: migration.id, AppliedAt: time.Now(), Checksum: migration.fileChecksum()}
+				return tx.Table(recordsTableName(m.options)).Clauses(clause.OnConflict{UpdateAll: true}).Create(&record).Error
+			})
+			if err != nil {
+				return err
+			}
+			m.applied = append(m.applied, AppliedMigration{ID: migration.id, Name: migration.name, Duration: time.Since(start)})
+			return nil
 		},
-		Rollback: func(db *gorm.DB) error {
-			tx := db.Begin()
-			defer tx.Rollback()
-			err := tx.Exec(migration.rollbackSQL).Error
+		Rollback: func(db *gorm.DB) (err error) {
+			start := time.Now()
+			m.emit(Event{Type: EventStarted, ID: migration.id, Name: migration.name, Rollback: true})
+			defer func() {
+				m.emitDone(migration, true, start, err)
+			}()
+			if migration.irreversible {
+				return fmt.Errorf("migration %s_%s is irreversible and can not be rolled back", migration.id, migration.name)
+			}
+			err = migration.loadDown()
 			if err != nil {
 				return err
 			}
-			return tx.Commit().Error
+			err = m.transaction(db, migration.downNoTransaction, func(tx *gorm.DB) error {
+				err := migration.runDown(tx, m.transformSQL)
+				if err != nil {
+					return err
+				}
+				err = m.unmarkSquashed(tx, migration)
+				if err != nil {
+					return err
+				}
+				return tx.Table(recordsTableName(m.options)).Delete(&migrationRecord{ID: migration.id}).Error
+			})
+			if err != nil {
+				return err
+			}
+			m.rolledBack = append(m.rolledBack, AppliedMigration{ID: migration.id, Name: migration.name})
+			return nil
 		},
 	}
 }
 
-func getMigrations(path string) (map[string]migration, error) {
-	migrations := make(map[string]migration)
-	migrationsFilter, err := regexp.Compile(`^\d+.*_up.sql$`)
+// getSQLTransform returns SQLTransform, leaving the SQL as it is when it is not set
+func getSQLTransform(dbConfig DBConfig) func(sql string) string {
+	if dbConfig.SQLTransform == nil {
+		return func(sql string) string {
+			return sql
+		}
+	}
+	return dbConfig.SQLTransform
+}
+
+func managerOptions(dbConfig DBConfig) *gormigrate.Options {
+	options := *gormigrate.DefaultOptions
+	if dbConfig.Options != nil {
+		options = *dbConfig.Options
+	}
+	if options.TableName == "" {
+		options.TableName = gormigrate.DefaultOptions.TableName
+	}
+	if options.IDColumnName == "" {
+		options.IDColumnName = gormigrate.DefaultOptions.IDColumnName
+	}
+	if options.IDColumnSize == 0 {
+		options.IDColumnSize = gormigrate.DefaultOptions.IDColumnSize
+	}
+	if dbConfig.TableName != "" {
+		options.TableName = dbConfig.TableName
+	}
+	if dbConfig.TableSchema != "" && !strings.Contains(options.TableName, ".") {
+		options.TableName = dbConfig.TableSchema + "." + options.TableName
+	}
+	if dbConfig.Transaction == TransactionPerRun {
+		options.UseTransaction = true
+	}
+	return &options
+}
+
+// createTableSchema creates the schema of a migrations table named like "schema.table" when it does not exist.
+// SQLite has no schemas, its tables named like that are on attached databases
+func createTableSchema(db *gorm.DB, options *gormigrate.Options) error {
+	schema, _, qualified := strings.Cut(options.TableName, ".")
+	if !qualified || db.Dialector.Name() == "sqlite" {
+		return nil
+	}
+	err := db.Exec("CREATE SCHEMA IF NOT EXISTS " + db.Statement.Quote(schema)).Error
+	if err != nil {
+		return fmt.Errorf("could not create schema %s: %w", schema, err)
+	}
+	return nil
+}
+
+func recordsTableName(options *gormigrate.Options) string {
+	return options.TableName + "_records"
+}
+
+// Migration is a migration loaded from the migrations folder
+type Migration struct {
+	ID      string
+	Name    string
+	UpSQL   string
+	DownSQL string
+}
+
+// LoadMigrations gets all migrations from given folder sorted by their ID, the order they are applied in
+func LoadMigrations(path string) ([]Migration, error) {
+	migrations, err := getMigrations(DBConfig{MigrationsFolderPath: path})
+	if err != nil {
+		return nil, err
+	}
+	loaded := make([]Migration, 0, len(migrations))
+	for _, migration := range migrations {
+		err = migration.load()
+		if err != nil {
+			return nil, err
+		}
+		loaded = append(loaded, Migration{
+			ID:      migration.id,
+			Name:    migration.name,
+			UpSQL:   migration.migrationSQL,
+			DownSQL: migration.rollbackSQL,
+		})
+	}
+	return loaded, nil
+}
+
+// getMigrations gets all migrations from the configured folder sorted by their ID
+func getMigrations(dbConfig DBConfig) ([]migration, error) {
+	migrations, problems, err := readMigrations(dbConfig)
 	if err != nil {
 		return nil, err
 	}
-	rollbackFilter, err := regexp.Compile(`^\d+.*_down.sql$`)
+	if len(problems.duplicatedFiles) > 0 {
+		return nil, problems.duplicatedFiles[0]
+	}
+	err = validateUpFiles(migrations)
 	if err != nil {
 		return nil, err
 	}
-	files, err := os.ReadDir(path)
+	if !dbConfig.AllowIncompletePairs {
+		err = validatePairs(migrations)
+		if err != nil {
+			return nil, err
+		}
+	}
+	err = addGoMigrations(dbConfig, migrations)
 	if err != nil {
 		return nil, err
 	}
-	for _, file := range files {
-		var foundMigration migration
-		if file.IsDir() {
+	sorted := sortMigrations(migrations)
+	err = validateIDs(sorted)
+	if err != nil {
+		return nil, err
+	}
+	return orderMigrations(dbConfig, sorted)
+}
+
+// fileProblems are the problems found on the files of the folder while reading its migrations
+type fileProblems struct {
+	duplicatedFiles []error
+	// misnamedFiles end like up or down files but do not start with a migration ID
+	misnamedFiles []string
+}
+
+// readMigrations reads the up and down files of the configured folder into their migrations by name
+func readMigrations(dbConfig DBConfig) (map[string]migration, fileProblems, error) {
+	migrations := make(map[string]migration)
+	problems := fileProblems{}
+	matcher, err := newFileMatcher(dbConfig)
+	if err != nil {
+		return nil, problems, err
+	}
+	dbConfig.MigrationsFS, err = migrationsFS(dbConfig)
+	if err != nil {
+		return nil, problems, err
+	}
+	folderPaths := migrationFolders(dbConfig)
+	for _, folderPath := range folderPaths {
+		err = readFolder(dbConfig, folderPath, len(folderPaths) > 1, matcher, migrations, &problems)
+		if err != nil {
+			return nil, problems, err
+		}
+	}
+	return migrations, problems, nil
+}
+
+// migrationFolders lists MigrationsFolderPath followed by MigrationsFolderPaths
+func migrationFolders(dbConfig DBConfig) []string {
+	if len(dbConfig.MigrationsFolderPaths) == 0 {
+		return []string{dbConfig.MigrationsFolderPath}
+	}
+	folderPaths := make([]string, 0, len(dbConfig.MigrationsFolderPaths)+1)
+	if dbConfig.MigrationsFolderPath != "" {
+		folderPaths = append(folderPaths, dbConfig.MigrationsFolderPath)
+	}
+	return append(folderPaths, dbConfig.MigrationsFolderPaths...)
+}
+
+// mainFolder is the folder of MigrationsFolderPath, or the first of MigrationsFolderPaths when it is not set,
+// where the lock and the ordering manifest are kept
+func mainFolder(dbConfig DBConfig) string {
+	return migrationFolders(dbConfig)[0]
+}
+
+// readFolder reads the up and down files of a folder into migrations. When reading many folders the
+// files are named by their path joined with their folder, so collisions across folders can be told apart
+func readFolder(dbConfig DBConfig, folderPath string, joinFolder bool, matcher *fileMatcher, migrations map[string]migration, problems *fileProblems) error {
+	fsys := dbConfig.MigrationsFS
+	files, err := listFiles(fsys, folderPath, dbConfig.Recursive)
+	if err != nil {
+		return err
+	}
+	for _, relativePath := range files {
+		filePath := relativePath
+		if joinFolder {
+			filePath = filepath.Join(folderPath, relativePath)
+		}
+		fileName := path.Base(filepath.ToSlash(filePath))
+		migrationID, migrationName, fileDialect, isMigration, ok := matcher.match(fileName)
+		if !ok {
+			if matcher.hasSuffix(fileName) {
+				problems.misnamedFiles = append(problems.misnamedFiles, filePath)
+			}
 			continue
 		}
-		fileName := file.Name()
-		filePath := path + "/" + fileName
-		content, err := os.ReadFile(filePath)
+		header, err := readHeader(fsys, folderPath, relativePath)
 		if err != nil {
-			fmt.Printf("Error reading file %s: %v\n", fileName, err)
+			getLogger(dbConfig).Error("Error reading file %s: %v", filePath, err)
 			continue
 		}
-		splitName := strings.Split(file.Name(), "_")
-		migrationID := splitName[0]
-		migrationName := strings.Join(splitName[:2], "_")
-		foundMigration = migrations[migrationName]
+		migrationKey := migrationID + "_" + migrationName
+		foundMigration := migrations[migrationKey]
 		foundMigration.id = migrationID
-		if migrationsFilter.MatchString(fileName) {
-			foundMigration.migrationSQL = string(content)
-		} else if rollbackFilter.MatchString(fileName) {
-			foundMigration.rollbackSQL = string(content)
-		} else {
+		foundMigration.name = migrationName
+		// the same file can only be found twice in different folders or subfolders
+		previousFile, previousDialect := foundMigration.downFile, foundMigration.downDialect
+		if isMigration {
+			previousFile, previousDialect = foundMigration.upFile, foundMigration.upDialect
+		}
+		if previousFile != "" && previousDialect == (fileDialect != "") {
+			problems.duplicatedFiles = append(problems.duplicatedFiles, fmt.Errorf("duplicated migration files: %s, %s", previousFile, filePath))
 			continue
 		}
-		migrations[migrationName] = foundMigration
+		// a dialect file always takes precedence over the generic one
+		if isMigration && (fileDialect != "" || !foundMigration.upDialect) {
+			foundMigration.upSource = fileSource(fsys, folderPath, relativePath)
+			foundMigration.upFile = filePath
+			foundMigration.expandEnv = dbConfig.ExpandEnv
+			foundMigration.upDialect = fileDialect != ""
+			foundMigration.upNoTransaction = hasDirective(header, noTransactionDirective)
+			foundMigration.squashes, _ = directiveValue(header, squashesDirective)
+			foundMigration.guard, _ = directiveValue(header, guardDirective)
+		} else if !isMigration && (fileDialect != "" || !foundMigration.downDialect) {
+			foundMigration.downSource = fileSource(fsys, folderPath, relativePath)
+			foundMigration.downFile = filePath
+			foundMigration.expandEnv = dbConfig.ExpandEnv
+			foundMigration.downDialect = fileDialect != ""
+			foundMigration.irreversible = hasDirective(header, irreversibleDirective)
+			foundMigration.downNoTransaction = hasDirective(header, noTransactionDirective)
+		}
+		migrations[migrationKey] = foundMigration
 	}
-	return migrations, nil
+	return nil
 }
 
-func newDatabase(dbConfig DBConfig) (*database, error) {
-	db, err := gorm.Open(dbConfig.Dialector, &gorm.Config{
-		SkipDefaultTransaction: true,
-		Logger:                 logger.Default.LogMode(logger.Silent),
+func validatePairs(migrations map[string]migration) error {
+	offenders := make([]string, 0)
+	for migrationName, migration := range migrations {
+		if migration.downFile == "" {
+			offenders = append(offenders, migrationName+" is missing its down file")
+		}
+	}
+	if len(offenders) > 0 {
+		sort.Strings(offenders)
+		return fmt.Errorf("incomplete migrations: %s", strings.Join(offenders, ", "))
+	}
+	return nil
+}
+
+// validateUpFiles makes sure every down file has its up file, as running the missing up file as
+// an empty migration would hide it
+func validateUpFiles(migrations map[string]migration) error {
+	offenders := make([]string, 0)
+	for _, migration := range migrations {
+		if migration.upFile == "" {
+			offenders = append(offenders, migration.downFile)
+		}
+	}
+	if len(offenders) > 0 {
+		sort.Strings(offenders)
+		return fmt.Errorf("down files without an up file: %s", strings.Join(offenders, ", "))
+	}
+	return nil
+}
+
+// validateIDs makes sure no two migrations share an ID, naming the files of every collision
+func validateIDs(sorted []migration) error {
+	offenders := make([]string, 0)
+	for i := 1; i < len(sorted); i++ {
+		if sorted[i].id != sorted[i-1].id {
+			continue
+		}
+		offenders = append(offenders, fmt.Sprintf("%s (%s, %s)", sorted[i].id, sorted[i-1].fileName(), sorted[i].fileName()))
+	}
+	if len(offenders) > 0 {
+		return fmt.Errorf("duplicated migration IDs: %s", strings.Join(offenders, ", "))
+	}
+	return nil
+}
+
+// checkRollback reports a migration missing its down file or irreversible, which can not be rolled back as
+// part of action
+func (m migration) checkRollback(action string) error {
+	if m.downFile == "" && m.goMigrate == nil {
+		return fmt.Errorf("migration %s_%s is missing its down file, can not %s", m.id, m.name, action)
+	}
+	if m.irreversible {
+		return fmt.Errorf("migration %s_%s is irreversible, can not %s", m.id, m.name, action)
+	}
+	return nil
+}
+
+// fileName is the name of the up file of the migration, or its down file when it has no up file
+func (m migration) fileName() string {
+	if m.goMigrate != nil {
+		return "Go migration " + m.id + "_" + m.name
+	}
+	if m.upFile != "" {
+		return m.upFile
+	}
+	return m.downFile
+}
+
+func readDir(fsys fs.FS, folderPath string) ([]fs.DirEntry, error) {
+	if fsys == nil {
+		return os.ReadDir(folderPath)
+	}
+	// paths inside of a fs.FS are always slash separated and can not go above its root
+	cleanPath := path.Clean(folderPath)
+	if !fs.ValidPath(cleanPath) {
+		return nil, fmt.Errorf("invalid migrations folder path %s", folderPath)
+	}
+	return fs.ReadDir(fsys, cleanPath)
+}
+
+// listFiles lists the paths of the files of the folder, relative to it. When recursive the files of
+// every subfolder are listed too. A folder that does not exist is reported wrapping ErrFolderNotFound
+func listFiles(fsys fs.FS, folderPath string, recursive bool) ([]string, error) {
+	files, err := walkFiles(fsys, folderPath, recursive)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %w", ErrFolderNotFound, err)
+	}
+	return files, err
+}
+
+// walkFiles reads the files listed by listFiles
+func walkFiles(fsys fs.FS, folderPath string, recursive bool) ([]string, error) {
+	files := make([]string, 0)
+	if !recursive {
+		entries, err := readDir(fsys, folderPath)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			if !entry.IsDir() {
+				files = append(files, entry.Name())
+			}
+		}
+		return files, nil
+	}
+	if fsys == nil {
+		err := filepath.WalkDir(folderPath, func(filePath string, entry fs.DirEntry, err error) error {
+			if err != nil || entry.IsDir() {
+				return err
+			}
+			relativePath, err := filepath.Rel(folderPath, filePath)
+			files = append(files, relativePath)
+			return err
+		})
+		return files, err
+	}
+	cleanPath := path.Clean(folderPath)
+	if !fs.ValidPath(cleanPath) {
+		return nil, fmt.Errorf("invalid migrations folder path %s", folderPath)
+	}
+	err := fs.WalkDir(fsys, cleanPath, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		if cleanPath != "." {
+			filePath = strings.TrimPrefix(filePath, cleanPath+"/")
+		}
+		files = append(files, filePath)
+		return nil
 	})
+	return files, err
+}
+
+func readFile(fsys fs.FS, folderPath string, fileName string) ([]byte, error) {
+	if fsys == nil {
+		return os.ReadFile(filepath.Join(folderPath, fileName))
+	}
+	return fs.ReadFile(fsys, path.Join(folderPath, fileName))
+}
+
+// fileExists reports if the file is in the folder, without reading it
+func fileExists(fsys fs.FS, folderPath string, fileName string) (bool, error) {
+	var err error
+	if fsys == nil {
+		_, err = os.Stat(filepath.Join(folderPath, fileName))
+	} else {
+		_, err = fs.Stat(fsys, path.Join(folderPath, fileName))
+	}
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func newDatabase(dbConfig DBConfig) (*database, error) {
+	logLevel := dbConfig.LogLevel
+	if logLevel == 0 {
+		logLevel = logger.Silent
+	}
+	if dbConfig.DB != nil {
+		db := dbConfig.DB.Session(&gorm.Session{
+			SkipDefaultTransaction: true,
+			Logger:                 dbConfig.DB.Logger.LogMode(logLevel),
+		})
+		return &database{db}, nil
+	}
+	gormConfig := gorm.Config{}
+	if dbConfig.GormConfig != nil {
+		gormConfig = *dbConfig.GormConfig
+	}
+	gormConfig.SkipDefaultTransaction = true
+	if gormConfig.Logger == nil {
+		gormConfig.Logger = logger.Default
+	}
+	gormConfig.Logger = gormConfig.Logger.LogMode(logLevel)
+	db, err := openDialector(dbConfig, &gormConfig)
 	if err != nil {
 		return nil, err
 	}
+	if len(dbConfig.ConnInitSQL) > 0 {
+		db, err = initConnection(db, dbConfig.ConnInitSQL)
+		if err != nil {
+			return nil, err
+		}
+	}
 	database := database{
 		db,
 	}
 	return &database, nil
 }
 
-func getChangesAuto(db *database, models []interface{}) string {
-	originalOut := os.Stdout
-	r, w, _ := os.Pipe()
-	os.Stdout = w
-	_ = db.Db.Session(&gorm.Session{DryRun: true}).AutoMigrate(models...)
-	_ = w.Close()
-	os.Stdout = originalOut
-	scanner := bufio.NewScanner(r)
+// DefaultAutoSQLFilter keeps every statement generated by the auto migration except for the
+// queries gorm runs to inspect the current schema
+func DefaultAutoSQLFilter(statement string) bool {
+	return !schemaProbeFilter.MatchString(strings.TrimSpace(statement))
+}
+
+// DialectAutoSQLFilter returns a filter like DefaultAutoSQLFilter that only leaves out the schema queries of
+// the given dialect, one of sqlite, mysql or postgres, so a statement that only looks like a query of another
+// dialect is kept. Other dialects get DefaultAutoSQLFilter
+func DialectAutoSQLFilter(dialect string) func(statement string) bool {
+	probeFilter, ok := dialectProbeFilters[dialect]
+	if !ok {
+		return DefaultAutoSQLFilter
+	}
+	return func(statement string) bool {
+		return !probeFilter.MatchString(strings.TrimSpace(statement))
+	}
+}
+
+// getChangesAuto collects the SQL AutoMigrate would run for the models, returning its failures as errors,
+// panics included, so a broken model can not take down the program creating the migration
+func getChangesAuto(db *database, dbConfig DBConfig) (migrationSQL string, rollbackSQL string, err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			migrationSQL, rollbackSQL = "", ""
+			err = fmt.Errorf("auto migration failed: %v", recovered)
+		}
+	}()
+	filter := dbConfig.AutoSQLFilter
+	if filter == nil {
+		filter = DialectAutoSQLFilter(db.Db.Dialector.Name())
+	}
+	statements, err := captureStatements(db.Db, func(tx *gorm.DB) error {
+		return tx.AutoMigrate(getModels(dbConfig)...)
+	})
+	if err != nil {
+		return "", "", fmt.Errorf("auto migration failed: %w", err)
+	}
 	lines := ""
-	for scanner.Scan() {
-		text := scanner.Text()
-		if !strings.HasPrefix(strings.ToUpper(strings.TrimSpace(text)), "SELECT") {
+	for _, statement := range statements {
+		text := statement + ";"
+		if filter(text) {
 			lines += text + "\n"
 		}
 	}
-	_ = r.Close()
+	return lines, getRollbackSQL(lines), nil
+}
+
+// getModels merges Models with the ones of ModelsProvider, keeping the first model of each type
+func getModels(dbConfig DBConfig) []interface{} {
+	if dbConfig.ModelsProvider == nil {
+		return dbConfig.Models
+	}
+	models := make([]interface{}, 0, len(dbConfig.Models))
+	seen := make(map[reflect.Type]bool)
+	for _, model := range append(append([]interface{}{}, dbConfig.Models...), dbConfig.ModelsProvider()...) {
+		modelType := reflect.Indirect(reflect.ValueOf(model)).Type()
+		if seen[modelType] {
+			continue
+		}
+		seen[modelType] = true
+		models = append(models, model)
+	}
+	return models
+}
+
+// getRollbackSQL builds a best-effort inverse of the auto generated SQL, statements
+// that can not be inverted are left as TODO comments for the developer to fill in
+func getRollbackSQL(migrationSQL string) string {
+	statements := strings.Split(strings.TrimSpace(migrationSQL), "\n")
+	lines := ""
+	for i := len(statements) - 1; i >= 0; i-- {
+		statement := strings.TrimSpace(statements[i])
+		if statement == "" {
+			continue
+		}
+		if match := createTableFilter.FindStringSubmatch(statement); match != nil {
+			lines += fmt.Sprintf("DROP TABLE IF EXISTS %s;\n", match[1])
+			continue
+		}
+		if match := addColumnFilter.FindStringSubmatch(statement); match != nil && !addKeywords[strings.ToUpper(match[2])] {
+			lines += fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s;\n", match[1], match[2])
+			continue
+		}
+		lines += fmt.Sprintf("-- TODO: write rollback for: %s\n", statement)
+	}
 	return lines
 }
 
-func generateFiles(migration migration, folderPath string) error {
-	_, err := os.ReadDir(folderPath)
+// renderFile executes the template of a migration file, using LF line endings and ending it with a single newline
+func renderFile(tmpl *template.Template, data *templateStruct) ([]byte, error) {
+	var content bytes.Buffer
+	err := tmpl.Execute(&content, data)
 	if err != nil {
-		return fmt.Errorf("could not find dir %s", folderPath)
+		return nil, err
 	}
-	migrationFileName := fmt.Sprintf("%s/%s_%s_up.sql", folderPath, migration.id, migration.name)
-	rollbackFileName := fmt.Sprintf("%s/%s_%s_down.sql", folderPath, migration.id, migration.name)
-	migrationFile, err := os.Create(migrationFileName)
+	normalized := strings.ReplaceAll(content.String(), "\r\n", "\n")
+	return []byte(strings.TrimRight(normalized, "\n") + "\n"), nil
+}
+
+// maxIDAttempts is how many IDs are tried for a new migration before giving up
+const maxIDAttempts = 10
+
+// freeMigrationID returns id when no migration uses it yet, otherwise the ID of the next unit of time of IDFormat
+// that is free, which keeps the new migration sorted right after the one it collided with
+func freeMigrationID(dbConfig DBConfig, id string) (string, error) {
+	migrations, _, err := readMigrations(dbConfig)
 	if err != nil {
-		return err
+		return "", err
+	}
+	taken := make(map[string]bool, len(migrations))
+	for _, migration := range migrations {
+		taken[migration.id] = true
 	}
-	rollbackFile, err := os.Create(rollbackFileName)
+	candidate := id
+	for attempt := 0; attempt < maxIDAttempts; attempt++ {
+		if !taken[candidate] {
+			return candidate, nil
+		}
+		candidate, err = nextMigrationID(idFormat(dbConfig), candidate)
+		if err != nil {
+			return "", fmt.Errorf("could not find a free migration ID, %s is taken: %w", id, err)
+		}
+	}
+	return "", fmt.Errorf("could not find a free migration ID, %s is taken after %d attempts", id, maxIDAttempts)
+}
+
+// idSteps move a time forward by each unit a layout can have, from the smallest to the largest
+var idSteps = []func(time.Time) time.Time{
+	func(t time.Time) time.Time { return t.Add(time.Nanosecond) },
+	func(t time.Time) time.Time { return t.Add(time.Microsecond) },
+	func(t time.Time) time.Time { return t.Add(time.Millisecond) },
+	func(t time.Time) time.Time { return t.Add(time.Second) },
+	func(t time.Time) time.Time { return t.Add(time.Minute) },
+	func(t time.Time) time.Time { return t.Add(time.Hour) },
+	func(t time.Time) time.Time { return t.AddDate(0, 0, 1) },
+	func(t time.Time) time.Time { return t.AddDate(0, 1, 0) },
+	func(t time.Time) time.Time { return t.AddDate(1, 0, 0) },
+}
+
+// nextMigrationID is the ID one unit of the layout after id, like the next second for "20060102150405", which
+// sorts right after it both as text and as a number. The time parsed from id starts its smallest unit, so the
+// first step changing its ID is that unit
+func nextMigrationID(layout string, id string) (string, error) {
+	parsed, err := time.Parse(layout, id)
 	if err != nil {
-		return err
+		return "", err
 	}
-	defer func() {
-		_ = migrationFile.Close()
-		_ = rollbackFile.Close()
-	}()
-	// Parse and execute template
-	tmpl, err := template.New("migration").Parse(migrationTemplate)
+	for _, step := range idSteps {
+		if next := step(parsed).Format(layout); next != id {
+			return next, nil
+		}
+	}
+	return "", fmt.Errorf("layout %s has no time elements", layout)
+}
+
+// headerComment turns the configured header comment into SQL comment lines
+func headerComment(dbConfig DBConfig) string {
+	header := dbConfig.HeaderComment
+	if header == "" {
+		header = DefaultHeaderComment
+	}
+	lines := strings.Split(strings.ReplaceAll(header, "\r\n", "\n"), "\n")
+	for i, line := range lines {
+		if !strings.HasPrefix(line, "--") {
+			lines[i] = strings.TrimRight("-- "+line, " ")
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func generateFiles(migration migration, dbConfig DBConfig) (string, string, error) {
+	folderPath := dbConfig.MigrationsFolderPath
+	if dbConfig.Output == nil {
+		_, err := os.ReadDir(folderPath)
+		if err != nil {
+			return "", "", fmt.Errorf("could not find dir %s", folderPath)
+		}
+		migration.id, err = freeMigrationID(dbConfig, migration.id)
+		if err != nil {
+			return "", "", err
+		}
+	}
+	fileTemplate := migrationTemplate
+	if dbConfig.Template != "" {
+		fileTemplate = dbConfig.Template
+	}
+	tmpl, err := template.New("migration").Parse(fileTemplate)
 	if err != nil {
-		return err
+		return "", "", err
 	}
 	data := &templateStruct{
-		MigrationSQL: migration.migrationSQL,
+		ID:            migration.id,
+		Name:          migration.name,
+		CreatedAt:     migration.createdAt,
+		HeaderComment: headerComment(dbConfig),
+		MigrationSQL:  migration.migrationSQL,
 	}
-	err = tmpl.Execute(migrationFile, data)
+	migrationContent, err := renderFile(tmpl, data)
 	if err != nil {
-		return err
+		return "", "", err
 	}
 	data.MigrationSQL = migration.rollbackSQL
-	err = tmpl.Execute(rollbackFile, data)
+	rollbackContent, err := renderFile(tmpl, data)
 	if err != nil {
-		return err
+		return "", "", err
+	}
+	upSuffix, downSuffix := fileSuffixes(dbConfig)
+	upPath := filepath.Join(folderPath, migration.id+"_"+migration.name+upSuffix)
+	downPath := filepath.Join(folderPath, migration.id+"_"+migration.name+downSuffix)
+	if dbConfig.Output != nil {
+		_, err = fmt.Fprintf(dbConfig.Output, "-- %s\n%s-- %s\n%s", filepath.Base(upPath), migrationContent, filepath.Base(downPath), rollbackContent)
+		return upPath, downPath, err
+	}
+	err = writeFiles([]string{upPath, downPath}, [][]byte{migrationContent, rollbackContent}, dbConfig.FileMode)
+	if err != nil {
+		return "", "", err
+	}
+	return upPath, downPath, nil
+}
+
+// writeFiles writes each content to its path through a temporary file, renaming them into place only once all
+// of them are written so a failure, like a full disk, leaves neither a half written file nor an up file without
+// its down file behind. A zero mode leaves them with 0666 less the umask, any other is set as is
+func writeFiles(paths []string, contents [][]byte, mode os.FileMode) error {
+	tempPaths := make([]string, 0, len(paths))
+	defer func() {
+		for _, tempPath := range tempPaths {
+			_ = os.Remove(tempPath)
+		}
+	}()
+	for i, filePath := range paths {
+		file, err := createTempFile(filePath)
+		if err != nil {
+			return err
+		}
+		tempPaths = append(tempPaths, file.Name())
+		_, err = file.Write(contents[i])
+		if err == nil && mode != 0 {
+			err = file.Chmod(mode)
+		}
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return err
+		}
+	}
+	for i, tempPath := range tempPaths {
+		err := os.Rename(tempPath, paths[i])
+		if err != nil {
+			for _, renamed := range paths[:i] {
+				_ = os.Remove(renamed)
+			}
+			return err
+		}
 	}
 	return nil
 }
+
+// maxTempAttempts is how many random names are tried for a temporary file before giving up
+const maxTempAttempts = 100
+
+// createTempFile creates a temporary file next to filePath with 0666 less the umask, the mode os.Create gives. The
+// .tmp suffix keeps it from being read as a migration, and the random part of its name keeps one left behind by an
+// interrupted run from blocking the next
+func createTempFile(filePath string) (*os.File, error) {
+	for attempt := 0; ; attempt++ {
+		tempPath := filepath.Join(filepath.Dir(filePath), "."+filepath.Base(filePath)+strconv.FormatUint(uint64(rand.Uint32()), 10)+".tmp")
+		file, err := os.OpenFile(tempPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o666)
+		if errors.Is(err, fs.ErrExist) && attempt < maxTempAttempts {
+			continue
+		}
+		return file, err
+	}
+}
@@ -1,20 +1,40 @@
 package migrationhandler_test
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
+	"log"
 	"os"
+	"path/filepath"
+	"reflect"
 	"regexp"
+	"slices"
 	"strings"
+	"sync"
 	"testing"
+	"testing/fstest"
+	"time"
 
 	"github.com/glebarez/sqlite"
+	"github.com/go-gormigrate/gormigrate/v2"
 	migrationhandler "github.com/jvfrodrigues/gorm-migration-handler"
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
+	"gorm.io/gorm/schema"
 )
 
+// withoutDurations clears the durations of applied migrations, which change on every run, so they can be compared
+func withoutDurations(applied []migrationhandler.AppliedMigration) []migrationhandler.AppliedMigration {
+	cleared := make([]migrationhandler.AppliedMigration, 0, len(applied))
+	for _, migration := range applied {
+		migration.Duration = 0
+		cleared = append(cleared, migration)
+	}
+	return cleared
+}
+
 func tempDir(t *testing.T) string {
 	dir, err := os.MkdirTemp("./", "test_migrations")
 	if err != nil {
@@ -29,13 +49,6 @@ func TestCreateMigration(t *testing.T) {
 		t.Fatalf("test error: %v", err)
 	}
 	dialector := sqlite.Open("file::memory:?cache=shared")
-	dir := tempDir(t)
-	defer func() {
-		_ = os.RemoveAll(dir)
-	}()
-	if err != nil {
-		t.Fatalf("test error: %v", err)
-	}
 	tests := []struct {
 		name                   string
 		dbConfig               migrationhandler.DBConfig
@@ -46,7 +59,7 @@ func TestCreateMigration(t *testing.T) {
 			name: "Test if no models available, empty migration files are created",
 			dbConfig: migrationhandler.DBConfig{
 				Dialector:            dialector,
-				MigrationsFolderPath: "./" + dir,
+				MigrationsFolderPath: "./" + tempDir(t),
 			},
 			expectedMigrationLines: 0,
 			expectedError:          nil,
@@ -64,7 +77,7 @@ func TestCreateMigration(t *testing.T) {
 						Age:  30,
 					},
 				},
-				MigrationsFolderPath: "./" + dir,
+				MigrationsFolderPath: "./" + tempDir(t),
 			},
 			expectedMigrationLines: 1,
 			expectedError:          nil,
@@ -85,7 +98,7 @@ func TestCreateMigration(t *testing.T) {
 					DriverName: "my_mysql_driver",
 					DSN:        "gorm:gorm@tcp(localhost:9910)/gorm?charset=utf8&parseTime=True&loc=Local", // data source name, refer https://github.com/go-sql-driver/mysql#dsn-data-source-name
 				}),
-				MigrationsFolderPath: "./" + dir,
+				MigrationsFolderPath: "./" + tempDir(t),
 			},
 			expectedMigrationLines: 0,
 			expectedError:          nil,
@@ -93,7 +106,10 @@ func TestCreateMigration(t *testing.T) {
 	}
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			err := migrationhandler.CreateMigration(tc.dbConfig, "test")
+			defer func() {
+				_ = os.RemoveAll(tc.dbConfig.MigrationsFolderPath)
+			}()
+			_, _, err := migrationhandler.CreateMigration(tc.dbConfig, "test")
 			if err != nil && tc.expectedError != nil {
 				if err.Error() != tc.expectedError.Error() {
 					t.Errorf("expected: %+v, got: %+v", tc.expectedError, err)
@@ -127,9 +143,246 @@ func TestCreateMigration(t *testing.T) {
 	}
 }
 
+type testUser struct {
+	ID   uint
+	Name string
+}
+
+func TestCreateMigrationRollbackSQL(t *testing.T) {
+	rollbackFilter, err := regexp.Compile(`^\d+.*_down.sql$`)
+	if err != nil {
+		t.Fatalf("test error: %v", err)
+	}
+	dir := tempDir(t)
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+	dbConfig := migrationhandler.DBConfig{
+		Dialector:            sqlite.Open("file::memory:?cache=shared"),
+		Models:               []interface{}{&testUser{}},
+		MigrationsFolderPath: "./" + dir,
+	}
+	_, _, err = migrationhandler.CreateMigration(dbConfig, "test")
+	if err != nil {
+		t.Fatalf("test error: %v", err)
+	}
+	dirFiles, err := os.ReadDir(dbConfig.MigrationsFolderPath)
+	if err != nil {
+		t.Fatalf("test error: %v", err)
+	}
+	var rollback []byte
+	for _, entry := range dirFiles {
+		if entry.IsDir() || !rollbackFilter.MatchString(entry.Name()) {
+			continue
+		}
+		rollback, err = os.ReadFile(dbConfig.MigrationsFolderPath + "/" + entry.Name())
+		if err != nil {
+			t.Fatalf("test error: %v", err)
+		}
+	}
+	expected := "DROP TABLE IF EXISTS `test_users`;"
+	if !strings.Contains(string(rollback), expected) {
+		t.Errorf("expected rollback to contain: %v, got: %v", expected, string(rollback))
+	}
+}
+
+func TestCreateMigrationRollbackAddColumn(t *testing.T) {
+	tests := []struct {
+		name          string
+		model         interface{}
+		existingTable string
+		expectedUp    string
+		expectedDown  string
+	}{
+		{
+			name:          "Test if an added column is dropped by the rollback",
+			model:         &testUser{},
+			existingTable: "CREATE TABLE test_users (id integer PRIMARY KEY AUTOINCREMENT)",
+			expectedUp:    "-- Write your SQL command here\nALTER TABLE `test_users` ADD `name` text;\n",
+			expectedDown:  "-- Write your SQL command here\nALTER TABLE `test_users` DROP COLUMN `name`;\n",
+		},
+		{
+			name:          "Test if a created table is dropped by the rollback",
+			model:         &testUser{},
+			existingTable: "",
+			expectedUp:    "-- Write your SQL command here\nCREATE TABLE `test_users` (`id` integer PRIMARY KEY AUTOINCREMENT,`name` text);\n",
+			expectedDown:  "-- Write your SQL command here\nDROP TABLE IF EXISTS `test_users`;\n",
+		},
+		{
+			name:          "Test if other statements are left as placeholders",
+			model:         &autoDiffProduct{},
+			existingTable: "CREATE TABLE auto_diff_products (id integer PRIMARY KEY AUTOINCREMENT, code text, price integer)",
+			expectedUp:    "-- Write your SQL command here\nCREATE INDEX `idx_auto_diff_products_code` ON `auto_diff_products`(`code`);\n",
+			expectedDown:  "-- Write your SQL command here\n-- TODO: write rollback for: CREATE INDEX `idx_auto_diff_products_code` ON `auto_diff_products`(`code`);\n",
+		},
+	}
+	for i, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := tempDir(t)
+			defer func() {
+				_ = os.RemoveAll(dir)
+			}()
+			db, err := gorm.Open(sqlite.Open(fmt.Sprintf("file:rollback_add_column_test_%d?mode=memory&cache=shared", i)), &gorm.Config{
+				Logger: logger.Default.LogMode(logger.Silent),
+			})
+			if err != nil {
+				t.Fatalf("test error: %v", err)
+			}
+			if tc.existingTable != "" {
+				if err := db.Exec(tc.existingTable).Error; err != nil {
+					t.Fatalf("test error: %v", err)
+				}
+			}
+			upPath, downPath, err := migrationhandler.CreateMigration(migrationhandler.DBConfig{
+				DB:                   db,
+				Models:               []interface{}{tc.model},
+				MigrationsFolderPath: "./" + dir,
+			}, "test")
+			if err != nil {
+				t.Fatalf("expected: %+v, got: %+v", nil, err)
+			}
+			up, err := os.ReadFile(upPath)
+			if err != nil {
+				t.Fatalf("test error: %v", err)
+			}
+			down, err := os.ReadFile(downPath)
+			if err != nil {
+				t.Fatalf("test error: %v", err)
+			}
+			if string(up) != tc.expectedUp {
+				t.Errorf("expected up: %q, got: %q", tc.expectedUp, string(up))
+			}
+			if string(down) != tc.expectedDown {
+				t.Errorf("expected down: %q, got: %q", tc.expectedDown, string(down))
+			}
+		})
+	}
+}
+
+func TestCreateMigrationPaths(t *testing.T) {
+	dir := tempDir(t)
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+	dbConfig := migrationhandler.DBConfig{
+		Dialector:            sqlite.Open("file:create_paths_test?mode=memory&cache=shared"),
+		MigrationsFolderPath: "./" + dir,
+	}
+	upPath, downPath, err := migrationhandler.CreateMigration(dbConfig, "paths")
+	if err != nil {
+		t.Fatalf("expected: %+v, got: %+v", nil, err)
+	}
+	for _, tc := range []struct {
+		filePath       string
+		expectedSuffix string
+	}{
+		{filePath: upPath, expectedSuffix: "_paths_up.sql"},
+		{filePath: downPath, expectedSuffix: "_paths_down.sql"},
+	} {
+		if filepath.Dir(tc.filePath) != filepath.Clean(dir) || !strings.HasSuffix(tc.filePath, tc.expectedSuffix) {
+			t.Errorf("expected a path in %s ending with %s, got: %s", dir, tc.expectedSuffix, tc.filePath)
+		}
+		_, err = os.Stat(tc.filePath)
+		if err != nil {
+			t.Errorf("expected file %s to exist, got: %+v", tc.filePath, err)
+		}
+	}
+}
+
+func TestCreateMigrationGormConfig(t *testing.T) {
+	dir := tempDir(t)
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+	gormConfig := &gorm.Config{
+		NamingStrategy: schema.NamingStrategy{TablePrefix: "app_"},
+	}
+	dbConfig := migrationhandler.DBConfig{
+		Dialector:            sqlite.Open("file:gorm_config_test?mode=memory&cache=shared"),
+		Models:               []interface{}{&testUser{}},
+		MigrationsFolderPath: "./" + dir,
+		GormConfig:           gormConfig,
+	}
+	upPath, _, err := migrationhandler.CreateMigration(dbConfig, "test")
+	if err != nil {
+		t.Fatalf("expected: %+v, got: %+v", nil, err)
+	}
+	content, err := os.ReadFile(upPath)
+	if err != nil {
+		t.Fatalf("test error: %v", err)
+	}
+	expected := "CREATE TABLE `app_test_users`"
+	if !strings.Contains(string(content), expected) {
+		t.Errorf("expected migration to contain: %v, got: %v", expected, string(content))
+	}
+	if gormConfig.SkipDefaultTransaction || gormConfig.Logger != nil {
+		t.Errorf("expected the given gorm config to be left untouched, got: %+v", gormConfig)
+	}
+}
+
+type panicModel struct {
+	ID uint
+}
+
+type invalidRelationModel struct {
+	ID    uint
+	Owner *testOwner
+}
+
+type testOwner struct {
+	Name string
+}
+
+func (panicModel) TableName() string {
+	panic("broken model")
+}
+
+func TestCreateMigrationAutoMigrateFailure(t *testing.T) {
+	tests := []struct {
+		name          string
+		models        []interface{}
+		expectedError error
+	}{
+		{
+			name:          "Test if a panic of the auto migration is returned as an error",
+			models:        []interface{}{&panicModel{}},
+			expectedError: errors.New("auto migration failed: broken model"),
+		},
+		{
+			name:          "Test if an error of the auto migration is returned",
+			models:        []interface{}{&invalidRelationModel{}},
+			expectedError: errors.New("auto migration failed: invalid field found for struct github.com/jvfrodrigues/gorm-migration-handler_test.invalidRelationModel's field Owner: define a valid foreign key for relations or implement the Valuer/Scanner interface"),
+		},
+	}
+	for i, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := tempDir(t)
+			defer func() {
+				_ = os.RemoveAll(dir)
+			}()
+			dbConfig := migrationhandler.DBConfig{
+				Dialector:            sqlite.Open(fmt.Sprintf("file:auto_failure_%v?mode=memory&cache=shared", i)),
+				Models:               tc.models,
+				MigrationsFolderPath: "./" + dir,
+			}
+			_, _, err := migrationhandler.CreateMigration(dbConfig, "test")
+			if err == nil || err.Error() != tc.expectedError.Error() {
+				t.Errorf("expected: %+v, got: %+v", tc.expectedError, err)
+			}
+			dirFiles, err := os.ReadDir(dir)
+			if err != nil {
+				t.Fatalf("test error: %v", err)
+			}
+			if len(dirFiles) != 0 {
+				t.Errorf("expected no files to be written, got: %+v", len(dirFiles))
+			}
+		})
+	}
+}
+
 func onEachRunMigrations(t *testing.T, dbConfig migrationhandler.DBConfig, migrationsToRun int) {
 	for i := 0; i < migrationsToRun; i++ {
-		err := migrationhandler.CreateMigration(dbConfig, fmt.Sprintf("test%v", i))
+		_, _, err := migrationhandler.CreateMigration(dbConfig, fmt.Sprintf("test%v", i))
 		if err != nil {
 			t.Fatalf("test error: %v", err)
 		}
@@ -148,6 +401,7 @@ func TestRunMigrations(t *testing.T) {
 	tests := []struct {
 		name            string
 		dbConfig        migrationhandler.DBConfig
+		files           map[string]string
 		migrationsToRun int
 		expectedError   error
 	}{
@@ -169,7 +423,7 @@ func TestRunMigrations(t *testing.T) {
 				MigrationsFolderPath: "./" + tempDir(t),
 			},
 			migrationsToRun: 0,
-			expectedError:   errors.New("connection to database failed, can not run migrations"),
+			expectedError:   errors.New(`connection to database failed, can not run migrations: sql: unknown driver "my_mysql_driver" (forgotten import?)`),
 		},
 		{
 			name: "Test if it errors on non existing migration folder",
@@ -178,7 +432,7 @@ func TestRunMigrations(t *testing.T) {
 				MigrationsFolderPath: "./non-existing-folder",
 			},
 			migrationsToRun: 0,
-			expectedError:   errors.New("open ./non-existing-folder: no such file or directory"),
+			expectedError:   errors.New("migrations folder not found: open ./non-existing-folder: no such file or directory"),
 		},
 		{
 			name: "Test if it errors on no migrations to run",
@@ -190,13 +444,28 @@ func TestRunMigrations(t *testing.T) {
 			expectedError:   errors.New("no migrations to run"),
 		},
 		{
-			name: "Test if it errors if there is more than one migration with the same ID",
+			name: "Test if migrations created in quick succession run successfully",
 			dbConfig: migrationhandler.DBConfig{
 				Dialector:            dialector,
 				MigrationsFolderPath: "./" + tempDir(t),
 			},
 			migrationsToRun: 2,
-			expectedError:   errors.New("gormigrate: Duplicated migration ID"),
+			expectedError:   nil,
+		},
+		{
+			name: "Test if it errors if there is more than one migration with the same ID",
+			dbConfig: migrationhandler.DBConfig{
+				Dialector:            dialector,
+				MigrationsFolderPath: "./" + tempDir(t),
+			},
+			files: map[string]string{
+				"1_first_up.sql":    "SELECT 1;",
+				"1_first_down.sql":  "SELECT 1;",
+				"1_second_up.sql":   "SELECT 1;",
+				"1_second_down.sql": "SELECT 1;",
+			},
+			migrationsToRun: 0,
+			expectedError:   errors.New("duplicated migration IDs: 1 (1_first_up.sql, 1_second_up.sql)"),
 		},
 	}
 	for _, tc := range tests {
@@ -205,10 +474,13 @@ func TestRunMigrations(t *testing.T) {
 				db.Exec("DROP TABLE 'migrations'")
 				_ = os.RemoveAll(tc.dbConfig.MigrationsFolderPath)
 			}()
+			for fileName, content := range tc.files {
+				writeMigration(t, tc.dbConfig.MigrationsFolderPath, fileName, content)
+			}
 			onEachRunMigrations(t, tc.dbConfig, tc.migrationsToRun)
-			err := migrationhandler.RunMigrations(tc.dbConfig)
-			if err != nil && tc.expectedError != nil {
-				if !strings.Contains(err.Error(), tc.expectedError.Error()) {
+			applied, err := migrationhandler.RunMigrations(tc.dbConfig)
+			if err != nil || tc.expectedError != nil {
+				if err == nil || tc.expectedError == nil || !strings.Contains(err.Error(), tc.expectedError.Error()) {
 					t.Errorf("expected: %+v, got: %+v", tc.expectedError, err)
 				}
 				return
@@ -218,24 +490,58 @@ func TestRunMigrations(t *testing.T) {
 			if count != int64(tc.migrationsToRun) {
 				t.Errorf("expected: %+v, got: %+v", tc.migrationsToRun, count)
 			}
+			if len(applied) != tc.migrationsToRun {
+				t.Errorf("expected applied: %+v, got: %+v", tc.migrationsToRun, len(applied))
+			}
 		})
 	}
 }
 
+func TestRunMigrationsApplied(t *testing.T) {
+	dir := tempDir(t)
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+	dbConfig := migrationhandler.DBConfig{
+		Dialector:            sqlite.Open("file:applied_test?mode=memory&cache=shared"),
+		MigrationsFolderPath: "./" + dir,
+	}
+	writeMigration(t, dir, "1_first_up.sql", "CREATE TABLE applied_first (id integer);")
+	writeMigration(t, dir, "1_first_down.sql", "DROP TABLE applied_first;")
+	applied, err := migrationhandler.RunMigrations(dbConfig)
+	if err != nil {
+		t.Fatalf("test error: %v", err)
+	}
+	if len(applied) != 1 || applied[0].ID != "1" || applied[0].Name != "first" {
+		t.Errorf("expected first migration to be applied, got: %+v", applied)
+	}
+	writeMigration(t, dir, "2_second_up.sql", "CREATE TABLE applied_second (id integer);")
+	writeMigration(t, dir, "2_second_down.sql", "DROP TABLE applied_second;")
+	applied, err = migrationhandler.RunMigrations(dbConfig)
+	if err != nil {
+		t.Fatalf("test error: %v", err)
+	}
+	if len(applied) != 1 || applied[0].ID != "2" || applied[0].Name != "second" {
+		t.Errorf("expected only second migration to be applied, got: %+v", applied)
+	}
+}
+
 func beforeEachRollback(t *testing.T, dialector gorm.Dialector) string {
 	dir := tempDir(t)
+	// every folder gets a migration with the same ID so they all share the same applied record
 	dbconfig := migrationhandler.DBConfig{
 		Dialector:            dialector,
 		MigrationsFolderPath: "./" + dir,
+		IDFormat:             "2006",
 	}
-	err := migrationhandler.CreateMigration(
+	_, _, err := migrationhandler.CreateMigration(
 		dbconfig,
 		"test",
 	)
 	if err != nil {
 		t.Fatalf("test error: %v", err)
 	}
-	err = migrationhandler.RunMigrations(dbconfig)
+	_, err = migrationhandler.RunMigrations(dbconfig)
 	if err != nil {
 		t.Fatalf("test error: %v", err)
 	}
@@ -272,7 +578,7 @@ func TestRollbackMigrations(t *testing.T) {
 			}),
 				MigrationsFolderPath: beforeEachRollback(t, dialector),
 			},
-			expectedError: errors.New("connection to database failed, can not run migrations"),
+			expectedError: errors.New(`connection to database failed, can not run migrations: sql: unknown driver "my_mysql_driver" (forgotten import?)`),
 		},
 		{
 			name: "Test if it errors on non existing migration folder",
@@ -280,7 +586,7 @@ func TestRollbackMigrations(t *testing.T) {
 				Dialector:            dialector,
 				MigrationsFolderPath: "./non-existing-folder",
 			},
-			expectedError: errors.New("open ./non-existing-folder: no such file or directory"),
+			expectedError: errors.New("migrations folder not found: open ./non-existing-folder: no such file or directory"),
 		},
 		{
 			name: "Test if it errors on no migrations to rollback",
@@ -296,7 +602,7 @@ func TestRollbackMigrations(t *testing.T) {
 			defer func() {
 				_ = os.RemoveAll(tc.dbConfig.MigrationsFolderPath)
 			}()
-			err := migrationhandler.RollbackMigration(tc.dbConfig)
+			_, err := migrationhandler.RollbackMigration(tc.dbConfig)
 			if err != nil && tc.expectedError != nil {
 				if err.Error() != tc.expectedError.Error() {
 					t.Errorf("expected: %+v, got: %+v", tc.expectedError, err)
@@ -311,3 +617,2665 @@ func TestRollbackMigrations(t *testing.T) {
 		})
 	}
 }
+
+func TestRunMigrationsFromFS(t *testing.T) {
+	dialector := sqlite.Open("file:fs_test?mode=memory&cache=shared")
+	db, err := gorm.Open(dialector, &gorm.Config{
+		SkipDefaultTransaction: true,
+		Logger:                 logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("test error: %v", err)
+	}
+	dbConfig := migrationhandler.DBConfig{
+		Dialector: dialector,
+		MigrationsFS: fstest.MapFS{
+			"migrations/1_create_up.sql":   {Data: []byte("CREATE TABLE fs_test (id integer);")},
+			"migrations/1_create_down.sql": {Data: []byte("DROP TABLE fs_test;")},
+		},
+		MigrationsFolderPath: "./migrations",
+	}
+	_, err = migrationhandler.RunMigrations(dbConfig)
+	if err != nil {
+		t.Fatalf("expected: %+v, got: %+v", nil, err)
+	}
+	if !db.Migrator().HasTable("fs_test") {
+		t.Errorf("expected table fs_test to exist after migration")
+	}
+	rolledBack, err := migrationhandler.RollbackMigration(dbConfig)
+	if err != nil {
+		t.Fatalf("expected: %+v, got: %+v", nil, err)
+	}
+	expected := migrationhandler.MigrationInfo{ID: "1", Name: "create"}
+	if rolledBack != expected {
+		t.Errorf("expected: %+v, got: %+v", expected, rolledBack)
+	}
+	if db.Migrator().HasTable("fs_test") {
+		t.Errorf("expected table fs_test to not exist after rollback")
+	}
+}
+
+func TestRunMigrationsConnectionError(t *testing.T) {
+	dbConfig := migrationhandler.DBConfig{
+		Dialector: mysql.New(mysql.Config{
+			DriverName: "my_mysql_driver",
+			DSN:        "gorm:gorm@tcp(localhost:9910)/gorm?charset=utf8&parseTime=True&loc=Local",
+		}),
+		MigrationsFolderPath: "./non-existing-folder",
+	}
+	_, err := migrationhandler.RunMigrations(dbConfig)
+	if !errors.Is(err, migrationhandler.ErrConnectionFailed) {
+		t.Errorf("expected: %+v, got: %+v", migrationhandler.ErrConnectionFailed, err)
+	}
+}
+
+func TestRollbackMigrationIrreversible(t *testing.T) {
+	dialector := sqlite.Open("file:irreversible_test?mode=memory&cache=shared")
+	db, err := gorm.Open(dialector, &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("test error: %v", err)
+	}
+	tests := []struct {
+		name          string
+		down          string
+		expectedError error
+		expectedTable bool
+	}{
+		{
+			name:          "Test if irreversible migrations are not rolled back",
+			down:          "-- irreversible\n-- the dropped data can not be recovered\n",
+			expectedError: errors.New("migration 1_drop is irreversible and can not be rolled back"),
+			expectedTable: true,
+		},
+		{
+			name:          "Test if the directive is only read from the header",
+			down:          "DROP TABLE irreversible_test;\n-- irreversible\n",
+			expectedError: nil,
+			expectedTable: false,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := tempDir(t)
+			defer func() {
+				db.Exec("DROP TABLE IF EXISTS irreversible_test")
+				db.Exec("DROP TABLE IF EXISTS migrations")
+				_ = os.RemoveAll(dir)
+			}()
+			dbConfig := migrationhandler.DBConfig{
+				Dialector:            dialector,
+				MigrationsFolderPath: "./" + dir,
+			}
+			writeMigration(t, dir, "1_drop_up.sql", "CREATE TABLE irreversible_test (id integer);")
+			writeMigration(t, dir, "1_drop_down.sql", tc.down)
+			_, err := migrationhandler.RunMigrations(dbConfig)
+			if err != nil {
+				t.Fatalf("test error: %v", err)
+			}
+			_, err = migrationhandler.RollbackMigration(dbConfig)
+			if err != nil || tc.expectedError != nil {
+				if err == nil || tc.expectedError == nil || err.Error() != tc.expectedError.Error() {
+					t.Errorf("expected: %+v, got: %+v", tc.expectedError, err)
+				}
+			}
+			if db.Migrator().HasTable("irreversible_test") != tc.expectedTable {
+				t.Errorf("expected table irreversible_test to exist: %+v", tc.expectedTable)
+			}
+		})
+	}
+}
+
+func TestRunMigrationsNoTransaction(t *testing.T) {
+	dialector := sqlite.Open("file:no_transaction_test?mode=memory&cache=shared")
+	tests := []struct {
+		name          string
+		up            string
+		transaction   migrationhandler.TransactionMode
+		expectedError error
+	}{
+		{
+			name:          "Test if statements that can not run in a transaction run with the directive",
+			up:            "-- no-transaction\nVACUUM;",
+			transaction:   migrationhandler.TransactionPerMigration,
+			expectedError: nil,
+		},
+		{
+			name:          "Test if migrations run in a transaction without the directive",
+			up:            "VACUUM;",
+			transaction:   migrationhandler.TransactionPerMigration,
+			expectedError: errors.New("cannot VACUUM from within a transaction"),
+		},
+		{
+			name:          "Test if it errors on the directive when the whole run is a transaction",
+			up:            "-- no-transaction\nVACUUM;",
+			transaction:   migrationhandler.TransactionPerRun,
+			expectedError: errors.New("migrations with a no-transaction directive can not run with TransactionPerRun"),
+		},
+	}
+	for i, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := tempDir(t)
+			defer func() {
+				_ = os.RemoveAll(dir)
+			}()
+			dbConfig := migrationhandler.DBConfig{
+				Dialector:            dialector,
+				MigrationsFolderPath: "./" + dir,
+				Transaction:          tc.transaction,
+				TableName:            fmt.Sprintf("migrations_%v", i),
+			}
+			writeMigration(t, dir, "1_vacuum_up.sql", tc.up)
+			writeMigration(t, dir, "1_vacuum_down.sql", "")
+			_, err := migrationhandler.RunMigrations(dbConfig)
+			if err != nil || tc.expectedError != nil {
+				if err == nil || tc.expectedError == nil || !strings.Contains(err.Error(), tc.expectedError.Error()) {
+					t.Errorf("expected: %+v, got: %+v", tc.expectedError, err)
+				}
+			}
+		})
+	}
+}
+
+func TestResetMigrations(t *testing.T) {
+	dialector := sqlite.Open("file:reset_test?mode=memory&cache=shared")
+	db, err := gorm.Open(dialector, &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("test error: %v", err)
+	}
+	tests := []struct {
+		name                 string
+		secondDown           string
+		allowIncompletePairs bool
+		expectedError        error
+		expectedApplied      int64
+	}{
+		{
+			name:            "Test if every migration is rolled back",
+			secondDown:      "DROP TABLE reset_second;",
+			expectedError:   nil,
+			expectedApplied: 0,
+		},
+		{
+			name:            "Test if nothing is rolled back when a migration is irreversible",
+			secondDown:      "-- irreversible\n",
+			expectedError:   errors.New("migration 2_second is irreversible, can not reset migrations"),
+			expectedApplied: 2,
+		},
+		{
+			name:                 "Test if nothing is rolled back when a down file is missing",
+			secondDown:           "",
+			allowIncompletePairs: true,
+			expectedError:        errors.New("migration 2_second is missing its down file, can not reset migrations"),
+			expectedApplied:      2,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := tempDir(t)
+			defer func() {
+				db.Exec("DROP TABLE IF EXISTS reset_first")
+				db.Exec("DROP TABLE IF EXISTS reset_second")
+				db.Exec("DROP TABLE IF EXISTS migrations")
+				_ = os.RemoveAll(dir)
+			}()
+			dbConfig := migrationhandler.DBConfig{
+				Dialector:            dialector,
+				MigrationsFolderPath: "./" + dir,
+				AllowIncompletePairs: tc.allowIncompletePairs,
+			}
+			writeMigration(t, dir, "1_first_up.sql", "CREATE TABLE reset_first (id integer);")
+			writeMigration(t, dir, "1_first_down.sql", "DROP TABLE reset_first;")
+			writeMigration(t, dir, "2_second_up.sql", "CREATE TABLE reset_second (id integer);")
+			if tc.secondDown != "" {
+				writeMigration(t, dir, "2_second_down.sql", tc.secondDown)
+			}
+			_, err := migrationhandler.RunMigrations(dbConfig)
+			if err != nil {
+				t.Fatalf("test error: %v", err)
+			}
+			err = migrationhandler.ResetMigrations(dbConfig)
+			if err != nil || tc.expectedError != nil {
+				if err == nil || tc.expectedError == nil || err.Error() != tc.expectedError.Error() {
+					t.Errorf("expected: %+v, got: %+v", tc.expectedError, err)
+				}
+			}
+			var count int64
+			db.Table("migrations").Count(&count)
+			if count != tc.expectedApplied {
+				t.Errorf("expected applied: %+v, got: %+v", tc.expectedApplied, count)
+			}
+		})
+	}
+}
+
+func TestRollbackN(t *testing.T) {
+	dialector := sqlite.Open("file:rollback_n_test?mode=memory&cache=shared")
+	db, err := gorm.Open(dialector, &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("test error: %v", err)
+	}
+	tests := []struct {
+		name                 string
+		n                    int
+		secondDown           string
+		allowIncompletePairs bool
+		expectedError        error
+		expectedApplied      int64
+	}{
+		{
+			name:            "Test if the last n migrations are rolled back",
+			n:               2,
+			secondDown:      "DROP TABLE rollback_n_second;",
+			expectedError:   nil,
+			expectedApplied: 1,
+		},
+		{
+			name:            "Test if nothing is rolled back when fewer than n migrations are applied",
+			n:               4,
+			secondDown:      "DROP TABLE rollback_n_second;",
+			expectedError:   errors.New("can not rollback 4 migrations, only 3 are applied"),
+			expectedApplied: 3,
+		},
+		{
+			name:                 "Test if nothing is rolled back when a down file is missing",
+			n:                    2,
+			secondDown:           "",
+			allowIncompletePairs: true,
+			expectedError:        errors.New("migration 2_second is missing its down file, can not rollback 2 migrations"),
+			expectedApplied:      3,
+		},
+		{
+			name:                 "Test if a migration missing its down file before the last n is ignored",
+			n:                    1,
+			secondDown:           "",
+			allowIncompletePairs: true,
+			expectedError:        nil,
+			expectedApplied:      2,
+		},
+		{
+			name:            "Test if n must be positive",
+			n:               0,
+			secondDown:      "DROP TABLE rollback_n_second;",
+			expectedError:   errors.New("invalid number of migrations to rollback 0"),
+			expectedApplied: 3,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := tempDir(t)
+			defer func() {
+				db.Exec("DROP TABLE IF EXISTS rollback_n_first")
+				db.Exec("DROP TABLE IF EXISTS rollback_n_second")
+				db.Exec("DROP TABLE IF EXISTS rollback_n_third")
+				db.Exec("DROP TABLE IF EXISTS migrations")
+				db.Exec("DROP TABLE IF EXISTS migrations_records")
+				_ = os.RemoveAll(dir)
+			}()
+			dbConfig := migrationhandler.DBConfig{
+				Dialector:            dialector,
+				MigrationsFolderPath: "./" + dir,
+				AllowIncompletePairs: tc.allowIncompletePairs,
+			}
+			writeMigration(t, dir, "1_first_up.sql", "CREATE TABLE rollback_n_first (id integer);")
+			writeMigration(t, dir, "1_first_down.sql", "DROP TABLE rollback_n_first;")
+			writeMigration(t, dir, "2_second_up.sql", "CREATE TABLE rollback_n_second (id integer);")
+			if tc.secondDown != "" {
+				writeMigration(t, dir, "2_second_down.sql", tc.secondDown)
+			}
+			writeMigration(t, dir, "3_third_up.sql", "CREATE TABLE rollback_n_third (id integer);")
+			writeMigration(t, dir, "3_third_down.sql", "DROP TABLE rollback_n_third;")
+			_, err := migrationhandler.RunMigrations(dbConfig)
+			if err != nil {
+				t.Fatalf("test error: %v", err)
+			}
+			err = migrationhandler.RollbackN(dbConfig, tc.n)
+			if err != nil || tc.expectedError != nil {
+				if err == nil || tc.expectedError == nil || err.Error() != tc.expectedError.Error() {
+					t.Errorf("expected: %+v, got: %+v", tc.expectedError, err)
+				}
+			}
+			var count int64
+			db.Table("migrations").Count(&count)
+			if count != tc.expectedApplied {
+				t.Errorf("expected applied: %+v, got: %+v", tc.expectedApplied, count)
+			}
+		})
+	}
+}
+
+func TestRunMigrationsExistingDB(t *testing.T) {
+	// a private in memory database only exists in its own connection, so the migrations
+	// can only be seen if they ran through the given pool
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("test error: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("test error: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	dir := tempDir(t)
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+	dbConfig := migrationhandler.DBConfig{
+		DB:                   db,
+		MigrationsFolderPath: "./" + dir,
+	}
+	writeMigration(t, dir, "1_create_up.sql", "CREATE TABLE existing_db_test (id integer);")
+	writeMigration(t, dir, "1_create_down.sql", "DROP TABLE existing_db_test;")
+	_, err = migrationhandler.RunMigrations(dbConfig)
+	if err != nil {
+		t.Fatalf("expected: %+v, got: %+v", nil, err)
+	}
+	if !db.Migrator().HasTable("existing_db_test") {
+		t.Errorf("expected table existing_db_test to exist after migration")
+	}
+	_, err = migrationhandler.RollbackMigration(dbConfig)
+	if err != nil {
+		t.Fatalf("expected: %+v, got: %+v", nil, err)
+	}
+	if db.Migrator().HasTable("existing_db_test") {
+		t.Errorf("expected table existing_db_test to not exist after rollback")
+	}
+}
+
+func TestRunMigrationsLogLevel(t *testing.T) {
+	tests := []struct {
+		name           string
+		logLevel       logger.LogLevel
+		expectedLogged bool
+	}{
+		{
+			name:           "Test if gorm is silent by default",
+			logLevel:       0,
+			expectedLogged: false,
+		},
+		{
+			name:           "Test if gorm logs the migration SQL on the Info level",
+			logLevel:       logger.Info,
+			expectedLogged: true,
+		},
+	}
+	for i, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var output bytes.Buffer
+			db, err := gorm.Open(sqlite.Open(fmt.Sprintf("file:log_level_test_%v?mode=memory&cache=shared", i)), &gorm.Config{
+				Logger: logger.New(log.New(&output, "", 0), logger.Config{Colorful: false}),
+			})
+			if err != nil {
+				t.Fatalf("test error: %v", err)
+			}
+			dir := tempDir(t)
+			defer func() {
+				_ = os.RemoveAll(dir)
+			}()
+			writeMigration(t, dir, "1_create_up.sql", "CREATE TABLE log_level_test (id integer);")
+			writeMigration(t, dir, "1_create_down.sql", "DROP TABLE log_level_test;")
+			_, err = migrationhandler.RunMigrations(migrationhandler.DBConfig{
+				DB:                   db,
+				MigrationsFolderPath: "./" + dir,
+				LogLevel:             tc.logLevel,
+			})
+			if err != nil {
+				t.Fatalf("expected: %+v, got: %+v", nil, err)
+			}
+			logged := strings.Contains(output.String(), "CREATE TABLE log_level_test")
+			if logged != tc.expectedLogged {
+				t.Errorf("expected migration SQL to be logged: %+v, got: %+v", tc.expectedLogged, output.String())
+			}
+		})
+	}
+}
+
+func TestRunMigrationsRecursive(t *testing.T) {
+	tests := []struct {
+		name            string
+		files           fstest.MapFS
+		recursive       bool
+		expectedApplied []migrationhandler.AppliedMigration
+		expectedError   error
+	}{
+		{
+			name: "Test if migrations of subfolders are sorted by ID across all of them",
+			files: fstest.MapFS{
+				"migrations/users/1_users_up.sql":     {Data: []byte("CREATE TABLE recursive_users (id integer);")},
+				"migrations/users/1_users_down.sql":   {Data: []byte("DROP TABLE recursive_users;")},
+				"migrations/orders/2_orders_up.sql":   {Data: []byte("CREATE TABLE recursive_orders (id integer);")},
+				"migrations/orders/2_orders_down.sql": {Data: []byte("DROP TABLE recursive_orders;")},
+				"migrations/3_root_up.sql":            {Data: []byte("CREATE TABLE recursive_root (id integer);")},
+				"migrations/3_root_down.sql":          {Data: []byte("DROP TABLE recursive_root;")},
+			},
+			recursive: true,
+			expectedApplied: []migrationhandler.AppliedMigration{
+				{ID: "1", Name: "users"},
+				{ID: "2", Name: "orders"},
+				{ID: "3", Name: "root"},
+			},
+			expectedError: nil,
+		},
+		{
+			name: "Test if subfolders are skipped by default",
+			files: fstest.MapFS{
+				"migrations/users/1_users_up.sql":   {Data: []byte("CREATE TABLE recursive_users (id integer);")},
+				"migrations/users/1_users_down.sql": {Data: []byte("DROP TABLE recursive_users;")},
+				"migrations/3_root_up.sql":          {Data: []byte("CREATE TABLE recursive_root (id integer);")},
+				"migrations/3_root_down.sql":        {Data: []byte("DROP TABLE recursive_root;")},
+			},
+			recursive: false,
+			expectedApplied: []migrationhandler.AppliedMigration{
+				{ID: "3", Name: "root"},
+			},
+			expectedError: nil,
+		},
+		{
+			name: "Test if it errors on the same migration in different subfolders",
+			files: fstest.MapFS{
+				"migrations/a/1_users_up.sql":   {Data: []byte("CREATE TABLE recursive_users (id integer);")},
+				"migrations/a/1_users_down.sql": {Data: []byte("DROP TABLE recursive_users;")},
+				"migrations/b/1_users_up.sql":   {Data: []byte("CREATE TABLE recursive_users (id integer);")},
+				"migrations/b/1_users_down.sql": {Data: []byte("DROP TABLE recursive_users;")},
+			},
+			recursive:     true,
+			expectedError: errors.New("duplicated migration files: a/1_users_down.sql, b/1_users_down.sql"),
+		},
+		{
+			name: "Test if it errors on the same ID in different subfolders",
+			files: fstest.MapFS{
+				"migrations/a/1_users_up.sql":    {Data: []byte("CREATE TABLE recursive_users (id integer);")},
+				"migrations/a/1_users_down.sql":  {Data: []byte("DROP TABLE recursive_users;")},
+				"migrations/b/1_orders_up.sql":   {Data: []byte("CREATE TABLE recursive_orders (id integer);")},
+				"migrations/b/1_orders_down.sql": {Data: []byte("DROP TABLE recursive_orders;")},
+			},
+			recursive:     true,
+			expectedError: errors.New("duplicated migration IDs: 1 (b/1_orders_up.sql, a/1_users_up.sql)"),
+		},
+	}
+	for i, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			applied, err := migrationhandler.RunMigrations(migrationhandler.DBConfig{
+				Dialector:            sqlite.Open(fmt.Sprintf("file:recursive_test_%v?mode=memory&cache=shared", i)),
+				MigrationsFS:         tc.files,
+				MigrationsFolderPath: "migrations",
+				Recursive:            tc.recursive,
+			})
+			if err != nil || tc.expectedError != nil {
+				if err == nil || tc.expectedError == nil || err.Error() != tc.expectedError.Error() {
+					t.Errorf("expected: %+v, got: %+v", tc.expectedError, err)
+				}
+				return
+			}
+			if fmt.Sprint(withoutDurations(applied)) != fmt.Sprint(tc.expectedApplied) {
+				t.Errorf("expected: %+v, got: %+v", tc.expectedApplied, applied)
+			}
+		})
+	}
+}
+
+func TestRunMigrationsFolderPaths(t *testing.T) {
+	tests := []struct {
+		name            string
+		files           fstest.MapFS
+		expectedApplied []migrationhandler.AppliedMigration
+		expectedError   error
+	}{
+		{
+			name: "Test if migrations of every folder are sorted by ID across all of them",
+			files: fstest.MapFS{
+				"app/2_orders_up.sql":      {Data: []byte("CREATE TABLE folders_orders (id integer);")},
+				"app/2_orders_down.sql":    {Data: []byte("DROP TABLE folders_orders;")},
+				"shared/1_users_up.sql":    {Data: []byte("CREATE TABLE folders_users (id integer);")},
+				"shared/1_users_down.sql":  {Data: []byte("DROP TABLE folders_users;")},
+				"plugin/3_events_up.sql":   {Data: []byte("CREATE TABLE folders_events (id integer);")},
+				"plugin/3_events_down.sql": {Data: []byte("DROP TABLE folders_events;")},
+			},
+			expectedApplied: []migrationhandler.AppliedMigration{
+				{ID: "1", Name: "users"},
+				{ID: "2", Name: "orders"},
+				{ID: "3", Name: "events"},
+			},
+			expectedError: nil,
+		},
+		{
+			name: "Test if it errors on the same migration in different folders",
+			files: fstest.MapFS{
+				"app/1_users_up.sql":       {Data: []byte("CREATE TABLE folders_users (id integer);")},
+				"app/1_users_down.sql":     {Data: []byte("DROP TABLE folders_users;")},
+				"shared/1_users_up.sql":    {Data: []byte("CREATE TABLE folders_users (id integer);")},
+				"shared/1_users_down.sql":  {Data: []byte("DROP TABLE folders_users;")},
+				"plugin/3_events_up.sql":   {Data: []byte("CREATE TABLE folders_events (id integer);")},
+				"plugin/3_events_down.sql": {Data: []byte("DROP TABLE folders_events;")},
+			},
+			expectedError: errors.New("duplicated migration files: app/1_users_down.sql, shared/1_users_down.sql"),
+		},
+		{
+			name: "Test if it errors on the same ID in different folders",
+			files: fstest.MapFS{
+				"app/1_orders_up.sql":      {Data: []byte("CREATE TABLE folders_orders (id integer);")},
+				"app/1_orders_down.sql":    {Data: []byte("DROP TABLE folders_orders;")},
+				"shared/1_users_up.sql":    {Data: []byte("CREATE TABLE folders_users (id integer);")},
+				"shared/1_users_down.sql":  {Data: []byte("DROP TABLE folders_users;")},
+				"plugin/3_events_up.sql":   {Data: []byte("CREATE TABLE folders_events (id integer);")},
+				"plugin/3_events_down.sql": {Data: []byte("DROP TABLE folders_events;")},
+			},
+			expectedError: errors.New("duplicated migration IDs: 1 (app/1_orders_up.sql, shared/1_users_up.sql)"),
+		},
+	}
+	for i, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			applied, err := migrationhandler.RunMigrations(migrationhandler.DBConfig{
+				Dialector:             sqlite.Open(fmt.Sprintf("file:folder_paths_test_%v?mode=memory&cache=shared", i)),
+				MigrationsFS:          tc.files,
+				MigrationsFolderPath:  "app",
+				MigrationsFolderPaths: []string{"shared", "plugin"},
+			})
+			if err != nil || tc.expectedError != nil {
+				if err == nil || tc.expectedError == nil || err.Error() != tc.expectedError.Error() {
+					t.Errorf("expected: %+v, got: %+v", tc.expectedError, err)
+				}
+				return
+			}
+			if fmt.Sprint(withoutDurations(applied)) != fmt.Sprint(tc.expectedApplied) {
+				t.Errorf("expected: %+v, got: %+v", tc.expectedApplied, applied)
+			}
+		})
+	}
+}
+
+func TestRunMigrationsRecursiveFolder(t *testing.T) {
+	dir := tempDir(t)
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+	err := os.Mkdir(filepath.Join(dir, "users"), 0o755)
+	if err != nil {
+		t.Fatalf("test error: %v", err)
+	}
+	writeMigration(t, dir, "users/2_users_up.sql", "CREATE TABLE users (id integer);")
+	writeMigration(t, dir, "users/2_users_down.sql", "DROP TABLE users;")
+	writeMigration(t, dir, "1_root_up.sql", "CREATE TABLE root (id integer);")
+	writeMigration(t, dir, "1_root_down.sql", "DROP TABLE root;")
+	dbConfig := migrationhandler.DBConfig{
+		Dialector:            sqlite.Open("file:recursive_folder_test?mode=memory&cache=shared"),
+		MigrationsFolderPath: "./" + dir,
+		Recursive:            true,
+		DryRun:               true,
+	}
+	applied, err := migrationhandler.RunMigrations(dbConfig)
+	if err != nil {
+		t.Fatalf("expected: %+v, got: %+v", nil, err)
+	}
+	expected := []migrationhandler.AppliedMigration{{ID: "1", Name: "root"}, {ID: "2", Name: "users"}}
+	if fmt.Sprint(withoutDurations(applied)) != fmt.Sprint(expected) {
+		t.Errorf("expected: %+v, got: %+v", expected, applied)
+	}
+}
+
+func TestRunMigrationsRange(t *testing.T) {
+	tests := []struct {
+		name            string
+		applied         string
+		fromID          string
+		toID            string
+		expectedApplied []migrationhandler.AppliedMigration
+		expectedError   error
+	}{
+		{
+			name:    "Test if only the migrations of the range are applied",
+			applied: "1",
+			fromID:  "2",
+			toID:    "3",
+			expectedApplied: []migrationhandler.AppliedMigration{
+				{ID: "2", Name: "second"},
+				{ID: "3", Name: "third"},
+			},
+			expectedError: nil,
+		},
+		{
+			name:          "Test if it errors when a migration before the range is pending",
+			fromID:        "2",
+			toID:          "3",
+			expectedError: errors.New("migration 1_first is pending before migration 2, apply it first"),
+		},
+		{
+			name:          "Test if it errors on a range ending before it starts",
+			fromID:        "3",
+			toID:          "2",
+			expectedError: errors.New("invalid range, migration 3 comes after migration 2"),
+		},
+		{
+			name:          "Test if it errors on range endpoints that do not exist",
+			fromID:        "1",
+			toID:          "9",
+			expectedError: errors.New("migration not found: 9"),
+		},
+	}
+	for i, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := tempDir(t)
+			defer func() {
+				_ = os.RemoveAll(dir)
+			}()
+			dbConfig := migrationhandler.DBConfig{
+				Dialector:            sqlite.Open(fmt.Sprintf("file:range_test_%v?mode=memory&cache=shared", i)),
+				MigrationsFolderPath: "./" + dir,
+			}
+			for id, name := range []string{"first", "second", "third", "fourth"} {
+				writeMigration(t, dir, fmt.Sprintf("%v_%v_up.sql", id+1, name), "")
+				writeMigration(t, dir, fmt.Sprintf("%v_%v_down.sql", id+1, name), "")
+			}
+			if tc.applied != "" {
+				err := migrationhandler.MigrateTo(dbConfig, tc.applied)
+				if err != nil {
+					t.Fatalf("test error: %v", err)
+				}
+			}
+			applied, err := migrationhandler.RunMigrationsRange(dbConfig, tc.fromID, tc.toID)
+			if err != nil || tc.expectedError != nil {
+				if err == nil || tc.expectedError == nil || err.Error() != tc.expectedError.Error() {
+					t.Errorf("expected: %+v, got: %+v", tc.expectedError, err)
+				}
+				return
+			}
+			if fmt.Sprint(withoutDurations(applied)) != fmt.Sprint(tc.expectedApplied) {
+				t.Errorf("expected: %+v, got: %+v", tc.expectedApplied, applied)
+			}
+		})
+	}
+}
+
+func TestRunMigrationsWithSummary(t *testing.T) {
+	dir := tempDir(t)
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+	logger := &testLogger{}
+	dbConfig := migrationhandler.DBConfig{
+		Dialector:            sqlite.Open("file:summary_test?mode=memory&cache=shared"),
+		MigrationsFolderPath: "./" + dir,
+		Logger:               logger,
+	}
+	writeMigration(t, dir, "1_first_up.sql", "")
+	writeMigration(t, dir, "1_first_down.sql", "")
+	_, err := migrationhandler.RunMigrations(dbConfig)
+	if err != nil {
+		t.Fatalf("test error: %v", err)
+	}
+	writeMigration(t, dir, "2_second_up.sql", "")
+	writeMigration(t, dir, "2_second_down.sql", "")
+	logger.messages = nil
+	summary, err := migrationhandler.RunMigrationsWithSummary(dbConfig)
+	if err != nil {
+		t.Fatalf("expected: %+v, got: %+v", nil, err)
+	}
+	if len(summary.Applied) != 1 || summary.Applied[0].Duration <= 0 {
+		t.Errorf("expected the applied migration to have a duration, got: %+v", summary.Applied)
+	}
+	summary.Applied = withoutDurations(summary.Applied)
+	expected := migrationhandler.RunSummary{
+		Applied: []migrationhandler.AppliedMigration{{ID: "2", Name: "second"}},
+		Skipped: []migrationhandler.AppliedMigration{{ID: "1", Name: "first"}},
+	}
+	if fmt.Sprint(summary) != fmt.Sprint(expected) {
+		t.Errorf("expected: %+v, got: %+v", expected, summary)
+	}
+	expectedMessages := []string{
+		"INFO Skipped 1 already applied: 1_first",
+		"INFO Migrations successful, 1 applied",
+	}
+	if fmt.Sprint(logger.messages) != fmt.Sprint(expectedMessages) {
+		t.Errorf("expected: %+v, got: %+v", expectedMessages, logger.messages)
+	}
+}
+
+func TestRollbackTo(t *testing.T) {
+	dialector := sqlite.Open("file:rollback_to_test?mode=memory&cache=shared")
+	db, err := gorm.Open(dialector, &gorm.Config{
+		SkipDefaultTransaction: true,
+		Logger:                 logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("test error: %v", err)
+	}
+	tests := []struct {
+		name                    string
+		migrationID             string
+		expectedMigrationsCount int64
+		expectedError           error
+	}{
+		{
+			name:                    "Test if migrations after the given ID are rolled back",
+			migrationID:             "1",
+			expectedMigrationsCount: 1,
+			expectedError:           nil,
+		},
+		{
+			name:                    "Test if it errors on non existing migration ID",
+			migrationID:             "9",
+			expectedMigrationsCount: 3,
+			expectedError:           errors.New("migration not found: 9"),
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := tempDir(t)
+			defer func() {
+				db.Exec("DROP TABLE 'migrations'")
+				_ = os.RemoveAll(dir)
+			}()
+			dbConfig := migrationhandler.DBConfig{
+				Dialector:            dialector,
+				MigrationsFolderPath: "./" + dir,
+			}
+			for i := 1; i <= 3; i++ {
+				writeMigration(t, dir, fmt.Sprintf("%v_test_up.sql", i), fmt.Sprintf("CREATE TABLE rollback_to_%v (id integer);", i))
+				writeMigration(t, dir, fmt.Sprintf("%v_test_down.sql", i), fmt.Sprintf("DROP TABLE rollback_to_%v;", i))
+			}
+			_, err := migrationhandler.RunMigrations(dbConfig)
+			if err != nil {
+				t.Fatalf("test error: %v", err)
+			}
+			err = migrationhandler.RollbackTo(dbConfig, tc.migrationID)
+			if err != nil || tc.expectedError != nil {
+				if err == nil || tc.expectedError == nil || err.Error() != tc.expectedError.Error() {
+					t.Errorf("expected: %+v, got: %+v", tc.expectedError, err)
+				}
+			}
+			var count int64
+			db.Table("migrations").Count(&count)
+			if count != tc.expectedMigrationsCount {
+				t.Errorf("expected: %+v, got: %+v", tc.expectedMigrationsCount, count)
+			}
+			if tc.expectedError == nil && db.Migrator().HasTable("rollback_to_2") {
+				t.Errorf("expected table rollback_to_2 to be rolled back")
+			}
+			_ = migrationhandler.RollbackTo(dbConfig, "1")
+			_, _ = migrationhandler.RollbackMigration(dbConfig)
+		})
+	}
+}
+
+func TestRunMigrationsTableName(t *testing.T) {
+	dialector := sqlite.Open("file:table_name_test?mode=memory&cache=shared")
+	db, err := gorm.Open(dialector, &gorm.Config{
+		SkipDefaultTransaction: true,
+		Logger:                 logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("test error: %v", err)
+	}
+	dir := tempDir(t)
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+	dbConfig := migrationhandler.DBConfig{
+		Dialector:            dialector,
+		MigrationsFolderPath: "./" + dir,
+		TableName:            "schema_migrations",
+	}
+	writeMigration(t, dir, "1_test_up.sql", "CREATE TABLE table_name_test (id integer);")
+	writeMigration(t, dir, "1_test_down.sql", "DROP TABLE table_name_test;")
+	_, err = migrationhandler.RunMigrations(dbConfig)
+	if err != nil {
+		t.Fatalf("test error: %v", err)
+	}
+	if db.Migrator().HasTable("migrations") {
+		t.Errorf("expected default migrations table to not be created")
+	}
+	var count int64
+	db.Table("schema_migrations").Count(&count)
+	if count != 1 {
+		t.Errorf("expected: %+v, got: %+v", 1, count)
+	}
+}
+
+func TestRunMigrationsIncompletePairs(t *testing.T) {
+	dialector := sqlite.Open("file:pairs_test?mode=memory&cache=shared")
+	tests := []struct {
+		name            string
+		files           map[string]string
+		allowIncomplete bool
+		expectedError   error
+	}{
+		{
+			name: "Test if it errors on migrations missing their down file",
+			files: map[string]string{
+				"1_first_up.sql":   "CREATE TABLE pairs_first (id integer);",
+				"1_first_down.sql": "DROP TABLE pairs_first;",
+				"2_second_up.sql":  "CREATE TABLE pairs_second (id integer);",
+			},
+			expectedError: errors.New("incomplete migrations: 2_second is missing its down file"),
+		},
+		{
+			name: "Test if incomplete migrations run when allowed",
+			files: map[string]string{
+				"2_second_up.sql": "CREATE TABLE pairs_second (id integer);",
+			},
+			allowIncomplete: true,
+			expectedError:   nil,
+		},
+		{
+			name: "Test if it errors on down files without their up file",
+			files: map[string]string{
+				"1_first_up.sql":   "CREATE TABLE pairs_first (id integer);",
+				"1_first_down.sql": "DROP TABLE pairs_first;",
+				"3_third_down.sql": "DROP TABLE pairs_third;",
+			},
+			expectedError: errors.New("down files without an up file: 3_third_down.sql"),
+		},
+		{
+			name: "Test if down files without their up file error even when incomplete migrations are allowed",
+			files: map[string]string{
+				"3_third_down.sql": "DROP TABLE pairs_third;",
+			},
+			allowIncomplete: true,
+			expectedError:   errors.New("down files without an up file: 3_third_down.sql"),
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := tempDir(t)
+			defer func() {
+				_ = os.RemoveAll(dir)
+			}()
+			for fileName, content := range tc.files {
+				writeMigration(t, dir, fileName, content)
+			}
+			_, err := migrationhandler.RunMigrations(migrationhandler.DBConfig{
+				Dialector:            dialector,
+				MigrationsFolderPath: "./" + dir,
+				AllowIncompletePairs: tc.allowIncomplete,
+			})
+			if err != nil || tc.expectedError != nil {
+				if err == nil || tc.expectedError == nil || err.Error() != tc.expectedError.Error() {
+					t.Errorf("expected: %+v, got: %+v", tc.expectedError, err)
+				}
+			}
+		})
+	}
+}
+
+func TestRunMigrationsMultiWordNames(t *testing.T) {
+	dialector := sqlite.Open("file:multi_word_test?mode=memory&cache=shared")
+	db, err := gorm.Open(dialector, &gorm.Config{
+		SkipDefaultTransaction: true,
+		Logger:                 logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("test error: %v", err)
+	}
+	dir := tempDir(t)
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+	dbConfig := migrationhandler.DBConfig{
+		Dialector:            dialector,
+		MigrationsFolderPath: "./" + dir,
+	}
+	writeMigration(t, dir, "1_add_user_table_up.sql", "CREATE TABLE multi_word_users (id integer, name text);")
+	writeMigration(t, dir, "1_add_user_table_down.sql", "DROP TABLE multi_word_users;")
+	writeMigration(t, dir, "2_add_user_index_up.sql", "CREATE INDEX idx_multi_word_users_name ON multi_word_users(name);")
+	writeMigration(t, dir, "2_add_user_index_down.sql", "DROP INDEX idx_multi_word_users_name;")
+	applied, err := migrationhandler.RunMigrations(dbConfig)
+	if err != nil {
+		t.Fatalf("test error: %v", err)
+	}
+	expected := []migrationhandler.AppliedMigration{
+		{ID: "1", Name: "add_user_table"},
+		{ID: "2", Name: "add_user_index"},
+	}
+	if fmt.Sprint(withoutDurations(applied)) != fmt.Sprint(expected) {
+		t.Errorf("expected: %+v, got: %+v", expected, applied)
+	}
+	_, err = migrationhandler.RollbackMigration(dbConfig)
+	if err != nil {
+		t.Fatalf("test error: %v", err)
+	}
+	if !db.Migrator().HasTable("multi_word_users") || db.Migrator().HasIndex("multi_word_users", "idx_multi_word_users_name") {
+		t.Errorf("expected only the index migration to be rolled back")
+	}
+}
+
+func TestRunMigrationsDryRun(t *testing.T) {
+	dialector := sqlite.Open("file:dry_run_test?mode=memory&cache=shared")
+	db, err := gorm.Open(dialector, &gorm.Config{
+		SkipDefaultTransaction: true,
+		Logger:                 logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("test error: %v", err)
+	}
+	dir := tempDir(t)
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+	dbConfig := migrationhandler.DBConfig{
+		Dialector:            dialector,
+		MigrationsFolderPath: "./" + dir,
+	}
+	writeMigration(t, dir, "1_first_up.sql", "CREATE TABLE dry_run_first (id integer);")
+	writeMigration(t, dir, "1_first_down.sql", "DROP TABLE dry_run_first;")
+	_, err = migrationhandler.RunMigrations(dbConfig)
+	if err != nil {
+		t.Fatalf("test error: %v", err)
+	}
+	writeMigration(t, dir, "2_second_up.sql", "CREATE TABLE dry_run_second (id integer);")
+	writeMigration(t, dir, "2_second_down.sql", "DROP TABLE dry_run_second;")
+	dbConfig.DryRun = true
+	applied, err := migrationhandler.RunMigrations(dbConfig)
+	if err != nil {
+		t.Fatalf("test error: %v", err)
+	}
+	if len(applied) != 1 || applied[0].ID != "2" {
+		t.Errorf("expected only second migration to be reported, got: %+v", applied)
+	}
+	if db.Migrator().HasTable("dry_run_second") {
+		t.Errorf("expected dry run to not execute the migration")
+	}
+	var count int64
+	db.Table("migrations").Count(&count)
+	if count != 1 {
+		t.Errorf("expected: %+v, got: %+v", 1, count)
+	}
+}
+
+func TestDefaultAutoSQLFilter(t *testing.T) {
+	tests := []struct {
+		name      string
+		statement string
+		expected  bool
+	}{
+		{
+			name:      "Test if sqlite schema probes are removed",
+			statement: "SELECT count(*) FROM sqlite_master WHERE type='table' AND name=\"users\";",
+			expected:  false,
+		},
+		{
+			name:      "Test if information schema probes are removed",
+			statement: "SELECT count(*) FROM information_schema.tables WHERE table_schema = 'gorm';",
+			expected:  false,
+		},
+		{
+			name:      "Test if database name probes are removed",
+			statement: "SELECT DATABASE();",
+			expected:  false,
+		},
+		{
+			name:      "Test if views are kept",
+			statement: "CREATE VIEW adults AS SELECT * FROM users WHERE age >= 18;",
+			expected:  true,
+		},
+		{
+			name:      "Test if insert select statements are kept",
+			statement: "INSERT INTO archived_users SELECT * FROM users;",
+			expected:  true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := migrationhandler.DefaultAutoSQLFilter(tc.statement)
+			if got != tc.expected {
+				t.Errorf("expected: %+v, got: %+v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestDialectAutoSQLFilter(t *testing.T) {
+	tests := []struct {
+		name      string
+		dialect   string
+		statement string
+		expected  bool
+	}{
+		{
+			name:      "Test if sqlite schema probes are removed on sqlite",
+			dialect:   "sqlite",
+			statement: "SELECT count(*) FROM sqlite_master WHERE type='table' AND name=\"users\";",
+			expected:  false,
+		},
+		{
+			name:      "Test if mysql schema probes are removed on mysql",
+			dialect:   "mysql",
+			statement: "SELECT SCHEMA_NAME from Information_schema.SCHEMATA where SCHEMA_NAME LIKE 'gorm%' ORDER BY SCHEMA_NAME='gorm' DESC,SCHEMA_NAME limit 1;",
+			expected:  false,
+		},
+		{
+			name:      "Test if mysql show statements are removed on mysql",
+			dialect:   "mysql",
+			statement: "SHOW INDEX FROM `users`;",
+			expected:  false,
+		},
+		{
+			name:      "Test if postgres schema probes are removed on postgres",
+			dialect:   "postgres",
+			statement: "SELECT description FROM pg_catalog.pg_description WHERE objsubid = 1;",
+			expected:  false,
+		},
+		{
+			name:      "Test if postgres schema name probes are removed on postgres",
+			dialect:   "postgres",
+			statement: "SELECT CURRENT_SCHEMA();",
+			expected:  false,
+		},
+		{
+			name:      "Test if statements looking like postgres probes are kept on mysql",
+			dialect:   "mysql",
+			statement: "SELECT * FROM pg_tables;",
+			expected:  true,
+		},
+		{
+			name:      "Test if statements looking like mysql probes are kept on sqlite",
+			dialect:   "sqlite",
+			statement: "SELECT DATABASE();",
+			expected:  true,
+		},
+		{
+			name:      "Test if other dialects use the default filter",
+			dialect:   "sqlserver",
+			statement: "SELECT count(*) FROM information_schema.tables;",
+			expected:  false,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := migrationhandler.DialectAutoSQLFilter(tc.dialect)(tc.statement)
+			if got != tc.expected {
+				t.Errorf("expected: %+v, got: %+v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestCreateMigrationWideTable(t *testing.T) {
+	// a table with enough columns to produce a CREATE TABLE statement longer than 64KB
+	fields := make([]reflect.StructField, 0)
+	for i := 0; i < 2000; i++ {
+		fields = append(fields, reflect.StructField{
+			Name: fmt.Sprintf("VeryLongColumnNameNumber%04d", i),
+			Type: reflect.TypeOf(""),
+		})
+	}
+	model := reflect.New(reflect.StructOf(fields)).Interface()
+	dir := tempDir(t)
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+	dbConfig := migrationhandler.DBConfig{
+		Dialector:            sqlite.Open("file:wide_table_test?mode=memory&cache=shared"),
+		Models:               []interface{}{model},
+		MigrationsFolderPath: "./" + dir,
+	}
+	_, _, err := migrationhandler.CreateMigration(dbConfig, "wide")
+	if err != nil {
+		t.Fatalf("test error: %v", err)
+	}
+	migrations, err := migrationhandler.LoadMigrations(dbConfig.MigrationsFolderPath)
+	if err != nil {
+		t.Fatalf("test error: %v", err)
+	}
+	if len(migrations) != 1 {
+		t.Fatalf("expected: %+v migrations, got: %+v", 1, len(migrations))
+	}
+	if len(migrations[0].UpSQL) <= 64*1024 {
+		t.Errorf("expected a statement longer than 64KB, got: %+v bytes", len(migrations[0].UpSQL))
+	}
+	lastColumn := "`very_long_column_name_number1999` text"
+	if !strings.Contains(migrations[0].UpSQL, lastColumn) {
+		t.Errorf("expected migration to contain its last column: %v", lastColumn)
+	}
+}
+
+func TestPreviewAutoMigration(t *testing.T) {
+	dir := tempDir(t)
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+	dbConfig := migrationhandler.DBConfig{
+		Dialector:            sqlite.Open("file:preview_test?mode=memory&cache=shared"),
+		Models:               []interface{}{&testUser{}},
+		MigrationsFolderPath: "./" + dir,
+	}
+	preview, err := migrationhandler.PreviewAutoMigration(dbConfig)
+	if err != nil {
+		t.Fatalf("expected: %+v, got: %+v", nil, err)
+	}
+	dirFiles, err := os.ReadDir(dbConfig.MigrationsFolderPath)
+	if err != nil {
+		t.Fatalf("test error: %v", err)
+	}
+	if len(dirFiles) != 0 {
+		t.Errorf("expected no files to be written, got: %+v", len(dirFiles))
+	}
+	_, _, err = migrationhandler.CreateMigration(dbConfig, "test")
+	if err != nil {
+		t.Fatalf("test error: %v", err)
+	}
+	migrations, err := migrationhandler.LoadMigrations(dbConfig.MigrationsFolderPath)
+	if err != nil {
+		t.Fatalf("test error: %v", err)
+	}
+	if preview == "" || !strings.Contains(migrations[0].UpSQL, preview) {
+		t.Errorf("expected preview to match the created migration, got: %v", preview)
+	}
+}
+
+func TestPreviewAutoMigrationModelsProvider(t *testing.T) {
+	tests := []struct {
+		name              string
+		models            []interface{}
+		modelsProvider    func() []interface{}
+		expectedMigration string
+	}{
+		{
+			name:   "Test if models of the provider are migrated",
+			models: nil,
+			modelsProvider: func() []interface{} {
+				return []interface{}{&testOrder{}}
+			},
+			expectedMigration: "CREATE TABLE `test_orders` (`id` integer PRIMARY KEY AUTOINCREMENT,`total` integer);\n",
+		},
+		{
+			name:   "Test if models of the provider are merged with the listed ones",
+			models: []interface{}{&testUser{}},
+			modelsProvider: func() []interface{} {
+				return []interface{}{testUser{}, &testOrder{}}
+			},
+			expectedMigration: "CREATE TABLE `test_users` (`id` integer PRIMARY KEY AUTOINCREMENT,`name` text);\n" +
+				"CREATE TABLE `test_orders` (`id` integer PRIMARY KEY AUTOINCREMENT,`total` integer);\n",
+		},
+	}
+	for i, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			migrationSQL, err := migrationhandler.PreviewAutoMigration(migrationhandler.DBConfig{
+				Dialector:      sqlite.Open(fmt.Sprintf("file:models_provider_test_%d?mode=memory&cache=shared", i)),
+				Models:         tc.models,
+				ModelsProvider: tc.modelsProvider,
+			})
+			if err != nil {
+				t.Fatalf("expected: %+v, got: %+v", nil, err)
+			}
+			if migrationSQL != tc.expectedMigration {
+				t.Errorf("expected: %+v, got: %+v", tc.expectedMigration, migrationSQL)
+			}
+		})
+	}
+}
+
+func TestMigrationSuffixes(t *testing.T) {
+	dialector := sqlite.Open("file:suffixes_test?mode=memory&cache=shared")
+	db, err := gorm.Open(dialector, &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("test error: %v", err)
+	}
+	tests := []struct {
+		name       string
+		upSuffix   string
+		downSuffix string
+	}{
+		{
+			name:       "Test if dotted suffixes are used to create and run migrations",
+			upSuffix:   ".up.sql",
+			downSuffix: ".down.sql",
+		},
+		{
+			name:       "Test if suffixes without an extension are used to create and run migrations",
+			upSuffix:   "_forward",
+			downSuffix: "_reverse",
+		},
+		{
+			name:       "Test if the longest matching suffix wins",
+			upSuffix:   ".sql",
+			downSuffix: ".rollback.sql",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := tempDir(t)
+			defer func() {
+				db.Exec("DROP TABLE IF EXISTS migrations")
+				_ = os.RemoveAll(dir)
+			}()
+			dbConfig := migrationhandler.DBConfig{
+				Dialector:            dialector,
+				MigrationsFolderPath: "./" + dir,
+				IDFormat:             "2006",
+				UpSuffix:             tc.upSuffix,
+				DownSuffix:           tc.downSuffix,
+			}
+			err := migrationhandler.CreateMigrationWithSQL(dbConfig, "suffix", "CREATE TABLE suffix_test (id integer);", "DROP TABLE suffix_test;")
+			if err != nil {
+				t.Fatalf("test error: %v", err)
+			}
+			id := time.Now().UTC().Format("2006")
+			for _, suffix := range []string{tc.upSuffix, tc.downSuffix} {
+				_, err = os.Stat(filepath.Join(dir, id+"_suffix"+suffix))
+				if err != nil {
+					t.Errorf("expected file with suffix %v to exist, got: %+v", suffix, err)
+				}
+			}
+			applied, err := migrationhandler.RunMigrations(dbConfig)
+			if err != nil || len(applied) != 1 {
+				t.Fatalf("expected: %+v applied, got: %+v %+v", 1, applied, err)
+			}
+			if !db.Migrator().HasTable("suffix_test") {
+				t.Errorf("expected table suffix_test to exist after migration")
+			}
+			_, err = migrationhandler.RollbackMigration(dbConfig)
+			if err != nil {
+				t.Fatalf("expected: %+v, got: %+v", nil, err)
+			}
+			if db.Migrator().HasTable("suffix_test") {
+				t.Errorf("expected table suffix_test to not exist after rollback")
+			}
+		})
+	}
+}
+
+func TestCreateMigrationAutoSQLFilter(t *testing.T) {
+	dir := tempDir(t)
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+	dbConfig := migrationhandler.DBConfig{
+		Dialector:            sqlite.Open("file:auto_filter_test?mode=memory&cache=shared"),
+		Models:               []interface{}{&testUser{}},
+		MigrationsFolderPath: "./" + dir,
+		AutoSQLFilter: func(statement string) bool {
+			return !strings.HasPrefix(statement, "CREATE TABLE")
+		},
+	}
+	_, _, err := migrationhandler.CreateMigration(dbConfig, "test")
+	if err != nil {
+		t.Fatalf("test error: %v", err)
+	}
+	dirFiles, err := os.ReadDir(dbConfig.MigrationsFolderPath)
+	if err != nil {
+		t.Fatalf("test error: %v", err)
+	}
+	for _, entry := range dirFiles {
+		content, err := os.ReadFile(dbConfig.MigrationsFolderPath + "/" + entry.Name())
+		if err != nil {
+			t.Fatalf("test error: %v", err)
+		}
+		if strings.Contains(string(content), "CREATE TABLE") {
+			t.Errorf("expected filtered statement to not be written to %v", entry.Name())
+		}
+	}
+}
+
+func TestCreateMigrationSkipEmpty(t *testing.T) {
+	tests := []struct {
+		name          string
+		skipEmpty     bool
+		expectedError error
+		expectedFiles int
+	}{
+		{
+			name:          "Test if empty migrations are written by default",
+			skipEmpty:     false,
+			expectedError: nil,
+			expectedFiles: 2,
+		},
+		{
+			name:          "Test if empty migrations are skipped with SkipEmpty",
+			skipEmpty:     true,
+			expectedError: migrationhandler.ErrNoChanges,
+			expectedFiles: 0,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := tempDir(t)
+			defer func() {
+				_ = os.RemoveAll(dir)
+			}()
+			dbConfig := migrationhandler.DBConfig{
+				Dialector:            sqlite.Open("file:skip_empty_test?mode=memory&cache=shared"),
+				MigrationsFolderPath: "./" + dir,
+				SkipEmpty:            tc.skipEmpty,
+			}
+			_, _, err := migrationhandler.CreateMigration(dbConfig, "test")
+			if !errors.Is(err, tc.expectedError) {
+				t.Errorf("expected: %+v, got: %+v", tc.expectedError, err)
+			}
+			dirFiles, err := os.ReadDir(dbConfig.MigrationsFolderPath)
+			if err != nil {
+				t.Fatalf("test error: %v", err)
+			}
+			if len(dirFiles) != tc.expectedFiles {
+				t.Errorf("expected: %+v files, got: %+v", tc.expectedFiles, len(dirFiles))
+			}
+		})
+	}
+}
+
+func TestCreateMigrationSkipAutoDiff(t *testing.T) {
+	tests := []struct {
+		name      string
+		dialector gorm.Dialector
+	}{
+		{
+			name:      "Test if the models are not diffed with a reachable database",
+			dialector: sqlite.Open("file:skip_auto_diff_test?mode=memory&cache=shared"),
+		},
+		{
+			name: "Test if the database is not connected to",
+			dialector: mysql.New(mysql.Config{
+				DriverName: "my_mysql_driver",
+				DSN:        "gorm:gorm@tcp(localhost:9910)/gorm?charset=utf8&parseTime=True&loc=Local",
+			}),
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := tempDir(t)
+			defer func() {
+				_ = os.RemoveAll(dir)
+			}()
+			logger := &testLogger{}
+			dbConfig := migrationhandler.DBConfig{
+				Dialector:            tc.dialector,
+				Models:               []interface{}{&testUser{}},
+				MigrationsFolderPath: "./" + dir,
+				Logger:               logger,
+				SkipAutoDiff:         true,
+			}
+			upPath, downPath, err := migrationhandler.CreateMigration(dbConfig, "test")
+			if err != nil {
+				t.Fatalf("expected: %+v, got: %+v", nil, err)
+			}
+			for _, filePath := range []string{upPath, downPath} {
+				content, err := os.ReadFile(filePath)
+				if err != nil {
+					t.Fatalf("test error: %v", err)
+				}
+				expected := "-- Write your SQL command here\n"
+				if string(content) != expected {
+					t.Errorf("expected: %q, got: %q", expected, string(content))
+				}
+			}
+			expectedMessages := []string{"INFO Migration 'test' created successfully."}
+			if fmt.Sprint(logger.messages) != fmt.Sprint(expectedMessages) {
+				t.Errorf("expected: %+v, got: %+v", expectedMessages, logger.messages)
+			}
+		})
+	}
+}
+
+type testOrder struct {
+	ID    uint
+	Total int
+}
+
+func TestCreateMigrationConcurrently(t *testing.T) {
+	tests := []struct {
+		name          string
+		model         interface{}
+		expectedTable string
+		otherTable    string
+	}{
+		{
+			name:          "Test if users migration only has the users table",
+			model:         &testUser{},
+			expectedTable: "`test_users`",
+			otherTable:    "`test_orders`",
+		},
+		{
+			name:          "Test if orders migration only has the orders table",
+			model:         &testOrder{},
+			expectedTable: "`test_orders`",
+			otherTable:    "`test_users`",
+		},
+	}
+	dirs := make([]string, len(tests))
+	errs := make([]error, len(tests))
+	var wg sync.WaitGroup
+	for i, tc := range tests {
+		dirs[i] = tempDir(t)
+		wg.Add(1)
+		go func(i int, model interface{}) {
+			defer wg.Done()
+			_, _, errs[i] = migrationhandler.CreateMigration(migrationhandler.DBConfig{
+				Dialector:            sqlite.Open(fmt.Sprintf("file:concurrent_test_%v?mode=memory&cache=shared", i)),
+				Models:               []interface{}{model},
+				MigrationsFolderPath: "./" + dirs[i],
+			}, "test")
+		}(i, tc.model)
+	}
+	wg.Wait()
+	for i, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			defer func() {
+				_ = os.RemoveAll(dirs[i])
+			}()
+			if errs[i] != nil {
+				t.Fatalf("test error: %v", errs[i])
+			}
+			dirFiles, err := os.ReadDir(dirs[i])
+			if err != nil {
+				t.Fatalf("test error: %v", err)
+			}
+			for _, entry := range dirFiles {
+				if !strings.HasSuffix(entry.Name(), "_up.sql") {
+					continue
+				}
+				content, err := os.ReadFile(dirs[i] + "/" + entry.Name())
+				if err != nil {
+					t.Fatalf("test error: %v", err)
+				}
+				if !strings.Contains(string(content), tc.expectedTable) || strings.Contains(string(content), tc.otherTable) {
+					t.Errorf("expected migration to only create %v, got: %v", tc.expectedTable, string(content))
+				}
+			}
+		})
+	}
+}
+
+func TestCreateMigrationIDCollision(t *testing.T) {
+	tests := []struct {
+		name          string
+		idFormat      string
+		existingIDs   []string
+		expectedID    string
+		expectedError error
+	}{
+		{
+			name:       "Test if a free ID is kept",
+			idFormat:   "20060102150405",
+			expectedID: "20240307123015",
+		},
+		{
+			name:        "Test if a taken ID moves to the next free second",
+			idFormat:    "20060102150405",
+			existingIDs: []string{"20240307123015", "20240307123016"},
+			expectedID:  "20240307123017",
+		},
+		{
+			name:        "Test if a taken ID moves to the next unit of the layout",
+			idFormat:    "2006",
+			existingIDs: []string{"2024"},
+			expectedID:  "2025",
+		},
+		{
+			name:          "Test if running out of attempts is an error",
+			idFormat:      "20060102150405",
+			existingIDs:   []string{"20240307123015", "20240307123016", "20240307123017", "20240307123018", "20240307123019", "20240307123020", "20240307123021", "20240307123022", "20240307123023", "20240307123024"},
+			expectedError: errors.New("could not find a free migration ID, 20240307123015 is taken after 10 attempts"),
+		},
+		{
+			name:          "Test if a taken ID of a layout without time elements is an error",
+			idFormat:      "7",
+			existingIDs:   []string{"7"},
+			expectedError: errors.New("could not find a free migration ID, 7 is taken: layout 7 has no time elements"),
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := tempDir(t)
+			defer func() {
+				_ = os.RemoveAll(dir)
+			}()
+			for _, id := range tc.existingIDs {
+				writeMigration(t, dir, id+"_existing_up.sql", "SELECT 1;")
+				writeMigration(t, dir, id+"_existing_down.sql", "SELECT 1;")
+			}
+			upPath, _, err := migrationhandler.CreateMigration(migrationhandler.DBConfig{
+				MigrationsFolderPath: "./" + dir,
+				IDFormat:             tc.idFormat,
+				SkipAutoDiff:         true,
+				NowFunc: func() time.Time {
+					return time.Date(2024, 3, 7, 12, 30, 15, 0, time.UTC)
+				},
+			}, "test")
+			if err != nil || tc.expectedError != nil {
+				if err == nil || tc.expectedError == nil || err.Error() != tc.expectedError.Error() {
+					t.Errorf("expected: %+v, got: %+v", tc.expectedError, err)
+				}
+				return
+			}
+			if filepath.Base(upPath) != tc.expectedID+"_test_up.sql" {
+				t.Errorf("expected migration ID %s, got: %s", tc.expectedID, upPath)
+			}
+		})
+	}
+}
+
+func TestCreateMigrationNowFunc(t *testing.T) {
+	now := func() time.Time {
+		return time.Date(2024, time.March, 7, 9, 30, 15, 123456000, time.FixedZone("BRT", -3*60*60))
+	}
+	tests := []struct {
+		name         string
+		idFormat     string
+		expectedUp   string
+		expectedDown string
+	}{
+		{
+			name:         "Test if the default ID comes from the given clock in UTC",
+			expectedUp:   "20240307123015.123456_test_up.sql",
+			expectedDown: "20240307123015.123456_test_down.sql",
+		},
+		{
+			name:         "Test if the ID format is applied to the given clock",
+			idFormat:     "20060102",
+			expectedUp:   "20240307_test_up.sql",
+			expectedDown: "20240307_test_down.sql",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := tempDir(t)
+			defer func() {
+				_ = os.RemoveAll(dir)
+			}()
+			upPath, downPath, err := migrationhandler.CreateMigration(migrationhandler.DBConfig{
+				MigrationsFolderPath: "./" + dir,
+				IDFormat:             tc.idFormat,
+				NowFunc:              now,
+				SkipAutoDiff:         true,
+			}, "test")
+			if err != nil {
+				t.Fatalf("expected: %+v, got: %+v", nil, err)
+			}
+			if filepath.Base(upPath) != tc.expectedUp || filepath.Base(downPath) != tc.expectedDown {
+				t.Errorf("expected: %+v and %+v, got: %+v and %+v", tc.expectedUp, tc.expectedDown, upPath, downPath)
+			}
+		})
+	}
+}
+
+func TestCreateMigrationWithSQL(t *testing.T) {
+	dir := tempDir(t)
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+	dbConfig := migrationhandler.DBConfig{
+		MigrationsFolderPath: "./" + dir,
+	}
+	err := migrationhandler.CreateMigrationWithSQL(dbConfig, "backfill_names", "UPDATE users SET name = 'unknown' WHERE name IS NULL;", "SELECT 1;")
+	if err != nil {
+		t.Fatalf("test error: %v", err)
+	}
+	dirFiles, err := os.ReadDir(dbConfig.MigrationsFolderPath)
+	if err != nil {
+		t.Fatalf("test error: %v", err)
+	}
+	if len(dirFiles) != 2 {
+		t.Fatalf("expected 2 files on folder got %v", len(dirFiles))
+	}
+	tests := []struct {
+		name            string
+		suffix          string
+		expectedContent string
+	}{
+		{
+			name:            "Test if up file has the given up SQL",
+			suffix:          "_backfill_names_up.sql",
+			expectedContent: "UPDATE users SET name = 'unknown' WHERE name IS NULL;",
+		},
+		{
+			name:            "Test if down file has the given down SQL",
+			suffix:          "_backfill_names_down.sql",
+			expectedContent: "SELECT 1;",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, entry := range dirFiles {
+				if !strings.HasSuffix(entry.Name(), tc.suffix) {
+					continue
+				}
+				content, err := os.ReadFile(dbConfig.MigrationsFolderPath + "/" + entry.Name())
+				if err != nil {
+					t.Fatalf("test error: %v", err)
+				}
+				if !strings.Contains(string(content), tc.expectedContent) {
+					t.Errorf("expected: %v, got: %v", tc.expectedContent, string(content))
+				}
+				return
+			}
+			t.Errorf("expected a file ending with %v", tc.suffix)
+		})
+	}
+}
+
+func TestCreateMigrationOutput(t *testing.T) {
+	tests := []struct {
+		name             string
+		upSQL            string
+		downSQL          string
+		expectedOutput   string
+		expectedUpPath   string
+		expectedDownPath string
+	}{
+		{
+			name:    "Test if the files are written to the output instead of the folder",
+			upSQL:   "CREATE TABLE users (id integer);",
+			downSQL: "DROP TABLE users;",
+			expectedOutput: "-- 20240307123015.000000_add_users_up.sql\n-- Write your SQL command here\nCREATE TABLE users (id integer);\n" +
+				"-- 20240307123015.000000_add_users_down.sql\n-- Write your SQL command here\nDROP TABLE users;\n",
+			expectedUpPath:   filepath.Join("non-existing-folder", "20240307123015.000000_add_users_up.sql"),
+			expectedDownPath: filepath.Join("non-existing-folder", "20240307123015.000000_add_users_down.sql"),
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var output bytes.Buffer
+			dbConfig := migrationhandler.DBConfig{
+				MigrationsFolderPath:  "./non-existing-folder",
+				CreateMigrationFolder: true,
+				SkipAutoDiff:          true,
+				Output:                &output,
+				Logger:                &testLogger{},
+				NowFunc: func() time.Time {
+					return time.Date(2024, 3, 7, 12, 30, 15, 0, time.UTC)
+				},
+			}
+			upPath, downPath, err := migrationhandler.CreateMigration(dbConfig, "add_users")
+			if err != nil {
+				t.Fatalf("expected: %+v, got: %+v", nil, err)
+			}
+			if upPath != tc.expectedUpPath || downPath != tc.expectedDownPath {
+				t.Errorf("expected: %+v %+v, got: %+v %+v", tc.expectedUpPath, tc.expectedDownPath, upPath, downPath)
+			}
+			output.Reset()
+			err = migrationhandler.CreateMigrationWithSQL(dbConfig, "add_users", tc.upSQL, tc.downSQL)
+			if err != nil {
+				t.Fatalf("expected: %+v, got: %+v", nil, err)
+			}
+			if output.String() != tc.expectedOutput {
+				t.Errorf("expected: %q, got: %q", tc.expectedOutput, output.String())
+			}
+			if _, err := os.Stat(dbConfig.MigrationsFolderPath); !os.IsNotExist(err) {
+				t.Errorf("expected the migrations folder to not be created, got: %+v", err)
+			}
+		})
+	}
+}
+
+// tempSuffix matches the end of the name of a temporary migration file with its random part
+var tempSuffix = regexp.MustCompile(`\.sql\d+\.tmp`)
+
+func TestCreateMigrationAtomic(t *testing.T) {
+	tests := []struct {
+		name          string
+		blockedFile   string
+		staleFile     string
+		expectedError error
+		expectedFiles []string
+	}{
+		{
+			name:          "Test if both files are written",
+			blockedFile:   "",
+			expectedError: nil,
+			expectedFiles: []string{"20240307123015_add_users_down.sql", "20240307123015_add_users_up.sql"},
+		},
+		{
+			name:          "Test if a temporary file left behind does not block new files",
+			staleFile:     ".20240307123015_add_users_up.sql.tmp",
+			expectedError: nil,
+			expectedFiles: []string{".20240307123015_add_users_up.sql.tmp", "20240307123015_add_users_down.sql", "20240307123015_add_users_up.sql"},
+		},
+		{
+			name:          "Test if the up file is removed when the down file can not be written",
+			blockedFile:   "20240307123015_add_users_down.sql",
+			expectedError: errors.New("rename %[1]s/.20240307123015_add_users_down.sql.tmp %[1]s/20240307123015_add_users_down.sql: file exists"),
+			expectedFiles: []string{"20240307123015_add_users_down.sql"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := tempDir(t)
+			defer func() {
+				_ = os.RemoveAll(dir)
+			}()
+			if tc.blockedFile != "" {
+				// a folder can not be replaced by a file, so renaming the down file into place fails
+				err := os.MkdirAll(filepath.Join(dir, tc.blockedFile, "blocked"), 0o755)
+				if err != nil {
+					t.Fatalf("test error: %v", err)
+				}
+			}
+			if tc.staleFile != "" {
+				writeMigration(t, dir, tc.staleFile, "CREATE TABLE")
+			}
+			err := migrationhandler.CreateMigrationWithSQL(migrationhandler.DBConfig{
+				MigrationsFolderPath: dir,
+				IDFormat:             "20060102150405",
+				Logger:               &testLogger{},
+				NowFunc: func() time.Time {
+					return time.Date(2024, 3, 7, 12, 30, 15, 0, time.UTC)
+				},
+			}, "add_users", "CREATE TABLE users (id integer);", "DROP TABLE users;")
+			if err != nil || tc.expectedError != nil {
+				expectedError := fmt.Sprintf(fmt.Sprint(tc.expectedError), filepath.Clean(dir))
+				// leaves out the random part of the name of the temporary file
+				if err != nil {
+					err = errors.New(tempSuffix.ReplaceAllString(err.Error(), ".sql.tmp"))
+				}
+				if err == nil || tc.expectedError == nil || err.Error() != expectedError {
+					t.Errorf("expected: %+v, got: %+v", expectedError, err)
+				}
+			}
+			entries, err := os.ReadDir(dir)
+			if err != nil {
+				t.Fatalf("test error: %v", err)
+			}
+			files := make([]string, 0, len(entries))
+			for _, entry := range entries {
+				files = append(files, entry.Name())
+			}
+			if !reflect.DeepEqual(files, tc.expectedFiles) {
+				t.Errorf("expected: %+v, got: %+v", tc.expectedFiles, files)
+			}
+		})
+	}
+}
+
+func TestCreateMigrationFileMode(t *testing.T) {
+	tests := []struct {
+		name         string
+		fileMode     os.FileMode
+		expectedMode os.FileMode
+	}{
+		{
+			name:         "Test if files get the mode of os.Create without a file mode",
+			fileMode:     0,
+			expectedMode: 0,
+		},
+		{
+			name:         "Test if files are group writable with 0664",
+			fileMode:     0o664,
+			expectedMode: 0o664,
+		},
+		{
+			name:         "Test if files are read only with 0444",
+			fileMode:     0o444,
+			expectedMode: 0o444,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := tempDir(t)
+			defer func() {
+				_ = os.RemoveAll(dir)
+			}()
+			upPath, downPath, err := migrationhandler.CreateMigration(migrationhandler.DBConfig{
+				MigrationsFolderPath: dir,
+				SkipAutoDiff:         true,
+				FileMode:             tc.fileMode,
+				Logger:               &testLogger{},
+			}, "add_users")
+			if err != nil {
+				t.Fatalf("expected: %+v, got: %+v", nil, err)
+			}
+			expectedMode := tc.expectedMode
+			if expectedMode == 0 {
+				// 0666 less the umask, whatever it is where the test runs
+				file, err := os.Create(filepath.Join(dir, "created"))
+				if err != nil {
+					t.Fatalf("test error: %v", err)
+				}
+				info, err := file.Stat()
+				_ = file.Close()
+				if err != nil {
+					t.Fatalf("test error: %v", err)
+				}
+				expectedMode = info.Mode().Perm()
+			}
+			for _, filePath := range []string{upPath, downPath} {
+				info, err := os.Stat(filePath)
+				if err != nil {
+					t.Fatalf("test error: %v", err)
+				}
+				if info.Mode().Perm() != expectedMode {
+					t.Errorf("expected: %v, got: %v", expectedMode, info.Mode().Perm())
+				}
+			}
+		})
+	}
+}
+
+func TestCreateMigrationName(t *testing.T) {
+	tests := []struct {
+		name          string
+		migrationName string
+		upSuffix      string
+		downPattern   string
+		expectedError error
+		expectedFiles int
+	}{
+		{
+			name:          "Test if names with letters, digits, underscores and hyphens are allowed",
+			migrationName: "add-users_table2",
+			expectedError: nil,
+			expectedFiles: 2,
+		},
+		{
+			name:          "Test if names with slashes are rejected",
+			migrationName: "users/add_table",
+			expectedError: errors.New(`invalid migration name "users/add_table", use only letters, digits, underscores and hyphens`),
+			expectedFiles: 0,
+		},
+		{
+			name:          "Test if names containing the up and down suffixes are allowed",
+			migrationName: "add_updated_at",
+			expectedError: nil,
+			expectedFiles: 2,
+		},
+		{
+			name:          "Test if names containing the down suffix are allowed",
+			migrationName: "add_download_count",
+			expectedError: nil,
+			expectedFiles: 2,
+		},
+		{
+			name:          "Test if names ending with the up suffix are rejected",
+			migrationName: "clean_up",
+			expectedError: errors.New(`invalid migration name "clean_up", it can not end with "_up" which ends the names of migration files`),
+			expectedFiles: 0,
+		},
+		{
+			name:          "Test if names ending with a configured suffix are rejected",
+			migrationName: "sign-up",
+			upSuffix:      "-up.sql",
+			expectedError: errors.New(`invalid migration name "sign-up", it can not end with "-up" which ends the names of migration files`),
+			expectedFiles: 0,
+		},
+		{
+			name:          "Test if names whose up file matches the down pattern are rejected",
+			migrationName: "revert_users",
+			upSuffix:      ".sql",
+			downPattern:   `^(?P<id>\d+)_revert_.+\.sql$`,
+			expectedError: errors.New(`invalid migration name "revert_users", its up file 1_revert_users.sql would be read as a down file`),
+			expectedFiles: 0,
+		},
+		{
+			name:          "Test if empty names are rejected",
+			migrationName: "",
+			expectedError: errors.New(`invalid migration name "", use only letters, digits, underscores and hyphens`),
+			expectedFiles: 0,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := tempDir(t)
+			defer func() {
+				_ = os.RemoveAll(dir)
+			}()
+			err := migrationhandler.CreateMigrationWithSQL(migrationhandler.DBConfig{
+				MigrationsFolderPath: "./" + dir,
+				UpSuffix:             tc.upSuffix,
+				DownPattern:          tc.downPattern,
+			}, tc.migrationName, "SELECT 1;", "SELECT 1;")
+			if err != nil || tc.expectedError != nil {
+				if err == nil || tc.expectedError == nil || err.Error() != tc.expectedError.Error() {
+					t.Errorf("expected: %+v, got: %+v", tc.expectedError, err)
+				}
+			}
+			dirFiles, err := os.ReadDir(dir)
+			if err != nil {
+				t.Fatalf("test error: %v", err)
+			}
+			if len(dirFiles) != tc.expectedFiles {
+				t.Errorf("expected: %+v, got: %+v", tc.expectedFiles, len(dirFiles))
+			}
+		})
+	}
+}
+
+func TestMigrationsFolderPath(t *testing.T) {
+	workingDir, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("test error: %v", err)
+	}
+	tests := []struct {
+		name       string
+		folderPath func(dir string) string
+	}{
+		{
+			name: "Test if folder path with trailing slash works",
+			folderPath: func(dir string) string {
+				return "./" + dir + "/"
+			},
+		},
+		{
+			name: "Test if relative folder path going up a directory works",
+			folderPath: func(dir string) string {
+				return "../" + filepath.Base(workingDir) + "/" + dir
+			},
+		},
+	}
+	for i, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := tempDir(t)
+			defer func() {
+				_ = os.RemoveAll(dir)
+			}()
+			dbConfig := migrationhandler.DBConfig{
+				Dialector:            sqlite.Open(fmt.Sprintf("file:folder_path_test_%v?mode=memory&cache=shared", i)),
+				MigrationsFolderPath: tc.folderPath(dir),
+			}
+			err := migrationhandler.CreateMigrationWithSQL(dbConfig, "test", "CREATE TABLE folder_path_test (id integer);", "DROP TABLE folder_path_test;")
+			if err != nil {
+				t.Fatalf("test error: %v", err)
+			}
+			applied, err := migrationhandler.RunMigrations(dbConfig)
+			if err != nil {
+				t.Fatalf("expected: %+v, got: %+v", nil, err)
+			}
+			if len(applied) != 1 {
+				t.Errorf("expected: %+v, got: %+v", 1, len(applied))
+			}
+		})
+	}
+}
+
+func TestMigrationsFSFolderPath(t *testing.T) {
+	_, err := migrationhandler.RunMigrations(migrationhandler.DBConfig{
+		Dialector:            sqlite.Open("file:fs_folder_path_test?mode=memory&cache=shared"),
+		MigrationsFS:         fstest.MapFS{},
+		MigrationsFolderPath: "../migrations",
+	})
+	expectedError := errors.New("invalid migrations folder path ../migrations")
+	if err == nil || err.Error() != expectedError.Error() {
+		t.Errorf("expected: %+v, got: %+v", expectedError, err)
+	}
+}
+
+func TestCreateMigrationFolder(t *testing.T) {
+	dir := tempDir(t)
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+	dbConfig := migrationhandler.DBConfig{
+		MigrationsFolderPath:  "./" + dir + "/nested/migrations",
+		CreateMigrationFolder: true,
+	}
+	err := migrationhandler.CreateMigrationWithSQL(dbConfig, "test", "", "")
+	if err != nil {
+		t.Fatalf("expected: %+v, got: %+v", nil, err)
+	}
+	dirFiles, err := os.ReadDir(dbConfig.MigrationsFolderPath)
+	if err != nil {
+		t.Fatalf("test error: %v", err)
+	}
+	if len(dirFiles) != 2 {
+		t.Errorf("expected 2 files on folder got %v", len(dirFiles))
+	}
+}
+
+func TestRunMigrationsOptions(t *testing.T) {
+	tests := []struct {
+		name            string
+		options         *gormigrate.Options
+		transaction     migrationhandler.TransactionMode
+		expectedApplied int
+		expectedTable   bool
+	}{
+		{
+			name:            "Test if migrations before a failing one stay applied by default",
+			options:         nil,
+			expectedApplied: 1,
+			expectedTable:   true,
+		},
+		{
+			name:            "Test if every migration is rolled back on failure when using gormigrate transaction",
+			options:         &gormigrate.Options{UseTransaction: true},
+			expectedApplied: 0,
+			expectedTable:   false,
+		},
+		{
+			name:            "Test if every migration is rolled back on failure when using a transaction per run",
+			transaction:     migrationhandler.TransactionPerRun,
+			expectedApplied: 0,
+			expectedTable:   false,
+		},
+	}
+	for i, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dialector := sqlite.Open(fmt.Sprintf("file:options_test_%v?mode=memory&cache=shared", i))
+			db, err := gorm.Open(dialector, &gorm.Config{
+				SkipDefaultTransaction: true,
+				Logger:                 logger.Default.LogMode(logger.Silent),
+			})
+			if err != nil {
+				t.Fatalf("test error: %v", err)
+			}
+			dir := tempDir(t)
+			defer func() {
+				_ = os.RemoveAll(dir)
+			}()
+			writeMigration(t, dir, "1_first_up.sql", "CREATE TABLE options_first (id integer);")
+			writeMigration(t, dir, "1_first_down.sql", "DROP TABLE options_first;")
+			writeMigration(t, dir, "2_second_up.sql", "CREATE TABLE options_first (id integer);")
+			writeMigration(t, dir, "2_second_down.sql", "")
+			applied, err := migrationhandler.RunMigrations(migrationhandler.DBConfig{
+				Dialector:            dialector,
+				MigrationsFolderPath: "./" + dir,
+				Options:              tc.options,
+				Transaction:          tc.transaction,
+			})
+			if err == nil {
+				t.Fatalf("expected second migration to fail")
+			}
+			if len(applied) != tc.expectedApplied {
+				t.Errorf("expected applied: %+v, got: %+v", tc.expectedApplied, len(applied))
+			}
+			if db.Migrator().HasTable("options_first") != tc.expectedTable {
+				t.Errorf("expected table to exist: %+v, got: %+v", tc.expectedTable, !tc.expectedTable)
+			}
+		})
+	}
+}
+
+func TestMigrateTo(t *testing.T) {
+	tests := []struct {
+		name                    string
+		migrationID             string
+		expectedMigrationsCount int64
+		expectedError           error
+	}{
+		{
+			name:                    "Test if migrations up to and including the given ID are applied",
+			migrationID:             "2",
+			expectedMigrationsCount: 2,
+			expectedError:           nil,
+		},
+		{
+			name:                    "Test if it errors on non existing migration ID",
+			migrationID:             "9",
+			expectedMigrationsCount: 0,
+			expectedError:           errors.New("migration not found: 9"),
+		},
+	}
+	for i, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dialector := sqlite.Open(fmt.Sprintf("file:migrate_to_test_%v?mode=memory&cache=shared", i))
+			db, err := gorm.Open(dialector, &gorm.Config{
+				SkipDefaultTransaction: true,
+				Logger:                 logger.Default.LogMode(logger.Silent),
+			})
+			if err != nil {
+				t.Fatalf("test error: %v", err)
+			}
+			dir := tempDir(t)
+			defer func() {
+				_ = os.RemoveAll(dir)
+			}()
+			for i := 1; i <= 3; i++ {
+				writeMigration(t, dir, fmt.Sprintf("%v_test_up.sql", i), fmt.Sprintf("CREATE TABLE migrate_to_%v (id integer);", i))
+				writeMigration(t, dir, fmt.Sprintf("%v_test_down.sql", i), fmt.Sprintf("DROP TABLE migrate_to_%v;", i))
+			}
+			err = migrationhandler.MigrateTo(migrationhandler.DBConfig{
+				Dialector:            dialector,
+				MigrationsFolderPath: "./" + dir,
+			}, tc.migrationID)
+			if err != nil || tc.expectedError != nil {
+				if err == nil || tc.expectedError == nil || err.Error() != tc.expectedError.Error() {
+					t.Errorf("expected: %+v, got: %+v", tc.expectedError, err)
+				}
+			}
+			var count int64
+			db.Table("migrations").Count(&count)
+			if count != tc.expectedMigrationsCount {
+				t.Errorf("expected: %+v, got: %+v", tc.expectedMigrationsCount, count)
+			}
+			if db.Migrator().HasTable("migrate_to_3") {
+				t.Errorf("expected migrations after the given ID to not be applied")
+			}
+		})
+	}
+}
+
+func TestRunOne(t *testing.T) {
+	tests := []struct {
+		name            string
+		migrationIDs    []string
+		expectedApplied []string
+		expectedError   error
+	}{
+		{
+			name:            "Test if only the given migration is applied",
+			migrationIDs:    []string{"3"},
+			expectedApplied: []string{"3"},
+			expectedError:   nil,
+		},
+		{
+			name:            "Test if the gaps are applied by the next run",
+			migrationIDs:    []string{"2", ""},
+			expectedApplied: []string{"1", "2", "3"},
+			expectedError:   nil,
+		},
+		{
+			name:            "Test if it errors on an applied migration",
+			migrationIDs:    []string{"2", "2"},
+			expectedApplied: []string{"2"},
+			expectedError:   errors.New("migration 2_test is already applied"),
+		},
+		{
+			name:            "Test if it errors on non existing migration ID",
+			migrationIDs:    []string{"9"},
+			expectedApplied: []string{},
+			expectedError:   errors.New("migration not found: 9"),
+		},
+	}
+	for i, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dialector := sqlite.Open(fmt.Sprintf("file:run_one_test_%v?mode=memory&cache=shared", i))
+			db, err := gorm.Open(dialector, &gorm.Config{
+				SkipDefaultTransaction: true,
+				Logger:                 logger.Default.LogMode(logger.Silent),
+			})
+			if err != nil {
+				t.Fatalf("test error: %v", err)
+			}
+			dir := tempDir(t)
+			defer func() {
+				_ = os.RemoveAll(dir)
+			}()
+			for i := 1; i <= 3; i++ {
+				writeMigration(t, dir, fmt.Sprintf("%v_test_up.sql", i), fmt.Sprintf("CREATE TABLE run_one_%v (id integer);", i))
+				writeMigration(t, dir, fmt.Sprintf("%v_test_down.sql", i), fmt.Sprintf("DROP TABLE run_one_%v;", i))
+			}
+			dbConfig := migrationhandler.DBConfig{
+				Dialector:            dialector,
+				MigrationsFolderPath: "./" + dir,
+				OutOfOrderCheck:      migrationhandler.CheckIgnore,
+			}
+			for _, migrationID := range tc.migrationIDs {
+				if migrationID == "" {
+					_, err = migrationhandler.RunMigrations(dbConfig)
+				} else {
+					err = migrationhandler.RunOne(dbConfig, migrationID)
+				}
+			}
+			if err != nil || tc.expectedError != nil {
+				if err == nil || tc.expectedError == nil || err.Error() != tc.expectedError.Error() {
+					t.Errorf("expected: %+v, got: %+v", tc.expectedError, err)
+				}
+			}
+			applied := make([]string, 0)
+			db.Table("migrations").Order("id").Pluck("id", &applied)
+			if !reflect.DeepEqual(applied, tc.expectedApplied) {
+				t.Errorf("expected applied: %+v, got: %+v", tc.expectedApplied, applied)
+			}
+			for _, migrationID := range tc.expectedApplied {
+				if !db.Migrator().HasTable("run_one_" + migrationID) {
+					t.Errorf("expected migration %s to have run", migrationID)
+				}
+			}
+		})
+	}
+}
+
+func TestRunMigrationsOutOfOrder(t *testing.T) {
+	tests := []struct {
+		name            string
+		outOfOrderCheck migrationhandler.CheckMode
+		expectedError   error
+		expectedApplied []string
+		expectedWarning bool
+	}{
+		{
+			name:            "Test if an out of order migration is an error by default",
+			outOfOrderCheck: migrationhandler.CheckError,
+			expectedError:   errors.New("out-of-order migration 2_test detected, migration 3 is already applied"),
+			expectedApplied: []string{"1", "3"},
+		},
+		{
+			name:            "Test if an out of order migration is applied with a warning",
+			outOfOrderCheck: migrationhandler.CheckWarn,
+			expectedApplied: []string{"1", "2", "3"},
+			expectedWarning: true,
+		},
+		{
+			name:            "Test if an out of order migration is applied when ignored",
+			outOfOrderCheck: migrationhandler.CheckIgnore,
+			expectedApplied: []string{"1", "2", "3"},
+		},
+	}
+	for i, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dialector := sqlite.Open(fmt.Sprintf("file:out_of_order_test_%v?mode=memory&cache=shared", i))
+			db, err := gorm.Open(dialector, &gorm.Config{
+				Logger: logger.Default.LogMode(logger.Silent),
+			})
+			if err != nil {
+				t.Fatalf("test error: %v", err)
+			}
+			dir := tempDir(t)
+			defer func() {
+				_ = os.RemoveAll(dir)
+			}()
+			testLogger := &testLogger{}
+			dbConfig := migrationhandler.DBConfig{
+				Dialector:            dialector,
+				MigrationsFolderPath: "./" + dir,
+				Logger:               testLogger,
+				OutOfOrderCheck:      tc.outOfOrderCheck,
+			}
+			for _, id := range []string{"1", "3"} {
+				writeMigration(t, dir, id+"_test_up.sql", "CREATE TABLE out_of_order_"+id+" (id integer);")
+				writeMigration(t, dir, id+"_test_down.sql", "DROP TABLE out_of_order_"+id+";")
+			}
+			_, err = migrationhandler.RunMigrations(dbConfig)
+			if err != nil {
+				t.Fatalf("test error: %v", err)
+			}
+			writeMigration(t, dir, "2_test_up.sql", "CREATE TABLE out_of_order_2 (id integer);")
+			writeMigration(t, dir, "2_test_down.sql", "DROP TABLE out_of_order_2;")
+			_, err = migrationhandler.RunMigrations(dbConfig)
+			if err != nil || tc.expectedError != nil {
+				if err == nil || tc.expectedError == nil || err.Error() != tc.expectedError.Error() {
+					t.Errorf("expected: %+v, got: %+v", tc.expectedError, err)
+				}
+			}
+			applied := make([]string, 0)
+			db.Table("migrations").Order("id").Pluck("id", &applied)
+			if !reflect.DeepEqual(applied, tc.expectedApplied) {
+				t.Errorf("expected applied: %+v, got: %+v", tc.expectedApplied, applied)
+			}
+			warned := slices.Contains(testLogger.messages, "WARN out-of-order migration 2_test detected, migration 3 is already applied")
+			if warned != tc.expectedWarning {
+				t.Errorf("expected warning: %v, got messages: %+v", tc.expectedWarning, testLogger.messages)
+			}
+		})
+	}
+}
+
+func TestCreateMigrationTemplate(t *testing.T) {
+	tests := []struct {
+		name            string
+		template        string
+		expectedContent func(id string) string
+		expectedError   error
+	}{
+		{
+			name:     "Test if custom template receives the migration fields",
+			template: "-- Migration: {{.Name}} ({{.ID}}) created in {{.CreatedAt.Year}}\n{{.MigrationSQL}}",
+			expectedContent: func(id string) string {
+				return fmt.Sprintf("-- Migration: test (%s) created in %s\nSELECT 1;\n", id, id[:4])
+			},
+		},
+		{
+			name:          "Test if it errors on invalid template",
+			template:      "{{.Name",
+			expectedError: errors.New("template: migration:1: unclosed action"),
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := tempDir(t)
+			defer func() {
+				_ = os.RemoveAll(dir)
+			}()
+			dbConfig := migrationhandler.DBConfig{
+				MigrationsFolderPath: "./" + dir,
+				Template:             tc.template,
+			}
+			err := migrationhandler.CreateMigrationWithSQL(dbConfig, "test", "SELECT 1;", "SELECT 1;")
+			if err != nil || tc.expectedError != nil {
+				if err == nil || tc.expectedError == nil || err.Error() != tc.expectedError.Error() {
+					t.Errorf("expected: %+v, got: %+v", tc.expectedError, err)
+				}
+				return
+			}
+			dirFiles, err := os.ReadDir(dbConfig.MigrationsFolderPath)
+			if err != nil {
+				t.Fatalf("test error: %v", err)
+			}
+			for _, entry := range dirFiles {
+				content, err := os.ReadFile(dbConfig.MigrationsFolderPath + "/" + entry.Name())
+				if err != nil {
+					t.Fatalf("test error: %v", err)
+				}
+				id := strings.Split(entry.Name(), "_")[0]
+				if string(content) != tc.expectedContent(id) {
+					t.Errorf("expected: %v, got: %v", tc.expectedContent(id), string(content))
+				}
+			}
+		})
+	}
+}
+
+func TestCreateMigrationLineEndings(t *testing.T) {
+	tests := []struct {
+		name            string
+		upSQL           string
+		expectedContent string
+	}{
+		{
+			name:            "Test if a missing final newline is added",
+			upSQL:           "SELECT 1;",
+			expectedContent: "-- Write your SQL command here\nSELECT 1;\n",
+		},
+		{
+			name:            "Test if extra final newlines are removed",
+			upSQL:           "SELECT 1;\n\n\n",
+			expectedContent: "-- Write your SQL command here\nSELECT 1;\n",
+		},
+		{
+			name:            "Test if CRLF line endings are turned into LF",
+			upSQL:           "SELECT 1;\r\nSELECT 2;\r\n",
+			expectedContent: "-- Write your SQL command here\nSELECT 1;\nSELECT 2;\n",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := tempDir(t)
+			defer func() {
+				_ = os.RemoveAll(dir)
+			}()
+			dbConfig := migrationhandler.DBConfig{
+				MigrationsFolderPath: "./" + dir,
+			}
+			err := migrationhandler.CreateMigrationWithSQL(dbConfig, "test", tc.upSQL, "")
+			if err != nil {
+				t.Fatalf("test error: %v", err)
+			}
+			dirFiles, err := os.ReadDir(dir)
+			if err != nil {
+				t.Fatalf("test error: %v", err)
+			}
+			for _, entry := range dirFiles {
+				content, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+				if err != nil {
+					t.Fatalf("test error: %v", err)
+				}
+				if len(content) == 0 || content[len(content)-1] != '\n' {
+					t.Errorf("expected %v to end with a newline, got: %q", entry.Name(), content)
+				}
+				if strings.HasSuffix(entry.Name(), "_up.sql") && string(content) != tc.expectedContent {
+					t.Errorf("expected: %q, got: %q", tc.expectedContent, content)
+				}
+			}
+		})
+	}
+}
+
+func TestCreateMigrationHeaderComment(t *testing.T) {
+	tests := []struct {
+		name            string
+		headerComment   string
+		template        string
+		expectedContent string
+	}{
+		{
+			name:            "Test if the default header comment is written",
+			expectedContent: "-- Write your SQL command here\nSELECT 1;\n",
+		},
+		{
+			name:            "Test if every line of the header comment is written as a comment",
+			headerComment:   "Escreva seu SQL aqui\n\n-- Copyright ACME",
+			expectedContent: "-- Escreva seu SQL aqui\n--\n-- Copyright ACME\nSELECT 1;\n",
+		},
+		{
+			name:            "Test if custom templates receive the header comment",
+			headerComment:   "Licensed under MIT",
+			template:        "{{.HeaderComment}}\n-- {{.Name}}\n{{.MigrationSQL}}",
+			expectedContent: "-- Licensed under MIT\n-- test\nSELECT 1;\n",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := tempDir(t)
+			defer func() {
+				_ = os.RemoveAll(dir)
+			}()
+			dbConfig := migrationhandler.DBConfig{
+				MigrationsFolderPath: "./" + dir,
+				HeaderComment:        tc.headerComment,
+				Template:             tc.template,
+			}
+			err := migrationhandler.CreateMigrationWithSQL(dbConfig, "test", "SELECT 1;", "")
+			if err != nil {
+				t.Fatalf("test error: %v", err)
+			}
+			migrations, err := migrationhandler.LoadMigrations(dir)
+			if err != nil {
+				t.Fatalf("test error: %v", err)
+			}
+			if len(migrations) != 1 || migrations[0].UpSQL != tc.expectedContent {
+				t.Errorf("expected: %q, got: %+v", tc.expectedContent, migrations)
+			}
+		})
+	}
+}
+
+func TestLoadMigrations(t *testing.T) {
+	dir := tempDir(t)
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+	writeMigration(t, dir, "3_third_up.sql", "SELECT 3;")
+	writeMigration(t, dir, "3_third_down.sql", "SELECT -3;")
+	writeMigration(t, dir, "1_first_up.sql", "SELECT 1;")
+	writeMigration(t, dir, "1_first_down.sql", "SELECT -1;")
+	writeMigration(t, dir, "2_second_up.sql", "SELECT 2;")
+	writeMigration(t, dir, "2_second_down.sql", "SELECT -2;")
+	migrations, err := migrationhandler.LoadMigrations("./" + dir)
+	if err != nil {
+		t.Fatalf("expected: %+v, got: %+v", nil, err)
+	}
+	expected := []migrationhandler.Migration{
+		{ID: "1", Name: "first", UpSQL: "SELECT 1;", DownSQL: "SELECT -1;"},
+		{ID: "2", Name: "second", UpSQL: "SELECT 2;", DownSQL: "SELECT -2;"},
+		{ID: "3", Name: "third", UpSQL: "SELECT 3;", DownSQL: "SELECT -3;"},
+	}
+	if fmt.Sprint(migrations) != fmt.Sprint(expected) {
+		t.Errorf("expected: %+v, got: %+v", expected, migrations)
+	}
+}
+
+func TestRollbackMigrationTransactionPerRun(t *testing.T) {
+	dialector := sqlite.Open("file:transaction_rollback_test?mode=memory&cache=shared")
+	db, err := gorm.Open(dialector, &gorm.Config{
+		SkipDefaultTransaction: true,
+		Logger:                 logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("test error: %v", err)
+	}
+	dir := tempDir(t)
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+	dbConfig := migrationhandler.DBConfig{
+		Dialector:            dialector,
+		MigrationsFolderPath: "./" + dir,
+		Transaction:          migrationhandler.TransactionPerRun,
+	}
+	writeMigration(t, dir, "1_first_up.sql", "CREATE TABLE transaction_rollback (id integer);")
+	writeMigration(t, dir, "1_first_down.sql", "DROP TABLE transaction_rollback;")
+	_, err = migrationhandler.RunMigrations(dbConfig)
+	if err != nil {
+		t.Fatalf("test error: %v", err)
+	}
+	_, err = migrationhandler.RollbackMigration(dbConfig)
+	if err != nil {
+		t.Fatalf("expected: %+v, got: %+v", nil, err)
+	}
+	if db.Migrator().HasTable("transaction_rollback") {
+		t.Errorf("expected table to be dropped on rollback")
+	}
+}
+
+func TestRollbackMigrationOrder(t *testing.T) {
+	tests := []struct {
+		name              string
+		files             []string
+		expectedRollbacks []string
+	}{
+		{
+			name:              "Test if the migration with the highest ID is rolled back first",
+			files:             []string{"20240103_alpha", "20240101_zeta", "20240105_beta", "20240102_omega", "20240104_gamma"},
+			expectedRollbacks: []string{"20240105_beta", "20240104_gamma", "20240103_alpha", "20240102_omega", "20240101_zeta"},
+		},
+		{
+			name:              "Test if IDs sharing a prefix are rolled back by their full ID",
+			files:             []string{"2024010_short", "20240101_long", "202401011_longer"},
+			expectedRollbacks: []string{"202401011_longer", "20240101_long", "2024010_short"},
+		},
+	}
+	for i, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := tempDir(t)
+			defer func() {
+				_ = os.RemoveAll(dir)
+			}()
+			dbConfig := migrationhandler.DBConfig{
+				Dialector:            sqlite.Open(fmt.Sprintf("file:rollback_order_test_%v?mode=memory&cache=shared", i)),
+				MigrationsFolderPath: "./" + dir,
+			}
+			for _, file := range tc.files {
+				writeMigration(t, dir, file+"_up.sql", "SELECT 1;")
+				writeMigration(t, dir, file+"_down.sql", "SELECT 1;")
+			}
+			_, err := migrationhandler.RunMigrations(dbConfig)
+			if err != nil {
+				t.Fatalf("test error: %v", err)
+			}
+			rollbacks := make([]string, 0, len(tc.files))
+			for range tc.files {
+				rolledBack, err := migrationhandler.RollbackMigration(dbConfig)
+				if err != nil {
+					t.Fatalf("expected: %+v, got: %+v", nil, err)
+				}
+				rollbacks = append(rollbacks, rolledBack.ID+"_"+rolledBack.Name)
+			}
+			if !reflect.DeepEqual(rollbacks, tc.expectedRollbacks) {
+				t.Errorf("expected: %+v, got: %+v", tc.expectedRollbacks, rollbacks)
+			}
+		})
+	}
+}
+
+func TestRunMigrationsDialectFiles(t *testing.T) {
+	dialector := sqlite.Open("file:dialect_test?mode=memory&cache=shared")
+	db, err := gorm.Open(dialector, &gorm.Config{
+		SkipDefaultTransaction: true,
+		Logger:                 logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("test error: %v", err)
+	}
+	dir := tempDir(t)
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+	dbConfig := migrationhandler.DBConfig{
+		Dialector:            dialector,
+		MigrationsFolderPath: "./" + dir,
+	}
+	writeMigration(t, dir, "1_test_up.sql", "CREATE TABLE dialect_generic (id integer);")
+	writeMigration(t, dir, "1_test_up.sqlite.sql", "CREATE TABLE dialect_sqlite (id integer);")
+	writeMigration(t, dir, "1_test_up.postgres.sql", "CREATE TABLE dialect_postgres (id serial);")
+	writeMigration(t, dir, "1_test_down.sql", "DROP TABLE dialect_sqlite;")
+	writeMigration(t, dir, "2_other_up.postgres.sql", "CREATE TABLE dialect_other (id serial);")
+	writeMigration(t, dir, "2_other_up.sql", "CREATE TABLE dialect_other_generic (id integer);")
+	writeMigration(t, dir, "2_other_down.sql", "DROP TABLE dialect_other_generic;")
+	_, err = migrationhandler.RunMigrations(dbConfig)
+	if err != nil {
+		t.Fatalf("expected: %+v, got: %+v", nil, err)
+	}
+	tests := []struct {
+		name          string
+		table         string
+		expectedTable bool
+	}{
+		{
+			name:          "Test if the file of the configured dialect is used",
+			table:         "dialect_sqlite",
+			expectedTable: true,
+		},
+		{
+			name:          "Test if the generic file is not used when there is a dialect file",
+			table:         "dialect_generic",
+			expectedTable: false,
+		},
+		{
+			name:          "Test if files of other dialects are not used",
+			table:         "dialect_other",
+			expectedTable: false,
+		},
+		{
+			name:          "Test if the generic file is used when there is no dialect file",
+			table:         "dialect_other_generic",
+			expectedTable: true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if db.Migrator().HasTable(tc.table) != tc.expectedTable {
+				t.Errorf("expected table %v to exist: %+v", tc.table, tc.expectedTable)
+			}
+		})
+	}
+	_, err = migrationhandler.RollbackMigration(dbConfig)
+	if err != nil {
+		t.Fatalf("expected: %+v, got: %+v", nil, err)
+	}
+}
+
+func TestRunMigrationsFolderPathsOnDisk(t *testing.T) {
+	tests := []struct {
+		name            string
+		orderFile       string
+		expectedApplied []migrationhandler.AppliedMigration
+	}{
+		{
+			name: "Test if folders are read without MigrationsFolderPath",
+			expectedApplied: []migrationhandler.AppliedMigration{
+				{ID: "1", Name: "users"},
+				{ID: "2", Name: "orders"},
+			},
+		},
+		{
+			name:      "Test if the ordering manifest is read from the first folder",
+			orderFile: "2\n1\n",
+			expectedApplied: []migrationhandler.AppliedMigration{
+				{ID: "2", Name: "orders"},
+				{ID: "1", Name: "users"},
+			},
+		},
+	}
+	for i, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			shared, plugin := tempDir(t), tempDir(t)
+			defer func() {
+				_ = os.RemoveAll(shared)
+				_ = os.RemoveAll(plugin)
+			}()
+			writeMigration(t, shared, "1_users_up.sql", "CREATE TABLE disk_folders_users (id integer);")
+			writeMigration(t, shared, "1_users_down.sql", "DROP TABLE disk_folders_users;")
+			writeMigration(t, plugin, "2_orders_up.sql", "CREATE TABLE disk_folders_orders (id integer);")
+			writeMigration(t, plugin, "2_orders_down.sql", "DROP TABLE disk_folders_orders;")
+			if tc.orderFile != "" {
+				writeMigration(t, shared, "migrations.order", tc.orderFile)
+			}
+			applied, err := migrationhandler.RunMigrations(migrationhandler.DBConfig{
+				Dialector:             sqlite.Open(fmt.Sprintf("file:folder_paths_disk_test_%v?mode=memory&cache=shared", i)),
+				MigrationsFolderPaths: []string{"./" + shared, "./" + plugin},
+			})
+			if err != nil {
+				t.Fatalf("expected: %+v, got: %+v", nil, err)
+			}
+			if fmt.Sprint(withoutDurations(applied)) != fmt.Sprint(tc.expectedApplied) {
+				t.Errorf("expected: %+v, got: %+v", tc.expectedApplied, applied)
+			}
+		})
+	}
+}
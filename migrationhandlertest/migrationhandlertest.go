@@ -0,0 +1,77 @@
+// Package migrationhandlertest provides helpers to run migrations against a disposable database in tests
+package migrationhandlertest
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	migrationhandler "github.com/jvfrodrigues/gorm-migration-handler"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+var databases atomic.Int64
+
+// testLogger writes the messages of the migration handler to the test log
+type testLogger struct {
+	t testing.TB
+}
+
+func (l testLogger) Info(format string, args ...interface{}) {
+	l.t.Logf(format, args...)
+}
+
+func (l testLogger) Warn(format string, args ...interface{}) {
+	l.t.Logf("WARN "+format, args...)
+}
+
+func (l testLogger) Error(format string, args ...interface{}) {
+	l.t.Logf("ERROR "+format, args...)
+}
+
+// NewTempConfig returns a DBConfig backed by an in memory SQLite database and an empty temporary migrations
+// folder, both removed when the test ends. Messages are written to the test log
+func NewTempConfig(t testing.TB) migrationhandler.DBConfig {
+	t.Helper()
+	name := strings.NewReplacer("/", "_", " ", "_").Replace(t.Name())
+	dsn := fmt.Sprintf("file:%s_%d?mode=memory&cache=shared", name, databases.Add(1))
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("could not open database: %v", err)
+	}
+	t.Cleanup(func() {
+		sqlDB, err := db.DB()
+		if err == nil {
+			_ = sqlDB.Close()
+		}
+	})
+	return migrationhandler.DBConfig{
+		DB:                   db,
+		MigrationsFolderPath: t.TempDir(),
+		Logger:               testLogger{t},
+	}
+}
+
+// WriteMigration creates a migration with the given up and down SQL on the migrations folder of dbConfig
+func WriteMigration(t testing.TB, dbConfig migrationhandler.DBConfig, name string, upSQL string, downSQL string) {
+	t.Helper()
+	err := migrationhandler.CreateMigrationWithSQL(dbConfig, name, upSQL, downSQL)
+	if err != nil {
+		t.Fatalf("could not create migration %s: %v", name, err)
+	}
+}
+
+// ApplyAll runs every pending migration of dbConfig, failing the test when any of them fails
+func ApplyAll(t testing.TB, dbConfig migrationhandler.DBConfig) []migrationhandler.AppliedMigration {
+	t.Helper()
+	applied, err := migrationhandler.RunMigrations(dbConfig)
+	if err != nil {
+		t.Fatalf("could not apply migrations: %v", err)
+	}
+	return applied
+}
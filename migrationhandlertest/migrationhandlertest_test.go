@@ -0,0 +1,23 @@
+package migrationhandlertest_test
+
+import (
+	"testing"
+
+	"github.com/jvfrodrigues/gorm-migration-handler/migrationhandlertest"
+)
+
+func TestApplyAll(t *testing.T) {
+	dbConfig := migrationhandlertest.NewTempConfig(t)
+	migrationhandlertest.WriteMigration(t, dbConfig, "first", "CREATE TABLE first (id integer);", "DROP TABLE first;")
+	applied := migrationhandlertest.ApplyAll(t, dbConfig)
+	if len(applied) != 1 {
+		t.Errorf("expected: %+v applied, got: %+v", 1, len(applied))
+	}
+	if !dbConfig.DB.Migrator().HasTable("first") {
+		t.Errorf("expected table first to exist after migration")
+	}
+	other := migrationhandlertest.NewTempConfig(t)
+	if other.DB.Migrator().HasTable("first") {
+		t.Errorf("expected every config to have a database of its own")
+	}
+}
@@ -0,0 +1,92 @@
+package migrationhandler
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// DefaultOrderFile is the name of the ordering manifest read from the main migrations folder, listing one
+// migration ID per line in the order they are applied. Blank lines and lines starting with "#" or "--" are left out
+const DefaultOrderFile = "migrations.order"
+
+// orderMigrations sorts the migrations in the order of the manifest of the folder, keeping them sorted by ID
+// when there is none. Every migration has to be listed and every listed ID has to be a migration
+func orderMigrations(dbConfig DBConfig, sorted []migration) ([]migration, error) {
+	orderFile := orderFileName(dbConfig)
+	exists, err := hasOrderFile(dbConfig)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return sorted, nil
+	}
+	fsys, err := migrationsFS(dbConfig)
+	if err != nil {
+		return nil, err
+	}
+	content, err := readFile(fsys, mainFolder(dbConfig), orderFile)
+	if err != nil {
+		return nil, fmt.Errorf("could not read %s: %w", orderFile, err)
+	}
+	byID := make(map[string]migration, len(sorted))
+	for _, migration := range sorted {
+		byID[migration.id] = migration
+	}
+	ordered := make([]migration, 0, len(sorted))
+	listed := make(map[string]bool)
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		id := strings.TrimSpace(scanner.Text())
+		if id == "" || strings.HasPrefix(id, "#") || strings.HasPrefix(id, "--") {
+			continue
+		}
+		if listed[id] {
+			return nil, fmt.Errorf("migration %s is listed more than once in %s", id, orderFile)
+		}
+		migration, ok := byID[id]
+		if !ok {
+			return nil, fmt.Errorf("migration %s is listed in %s but has no file", id, orderFile)
+		}
+		listed[id] = true
+		ordered = append(ordered, migration)
+	}
+	for _, migration := range sorted {
+		if !listed[migration.id] {
+			return nil, fmt.Errorf("migration %s_%s is missing from %s", migration.id, migration.name, orderFile)
+		}
+	}
+	return ordered, nil
+}
+
+// orderFileName is the name of the ordering manifest, OrderFile or DefaultOrderFile
+func orderFileName(dbConfig DBConfig) string {
+	if dbConfig.OrderFile == "" {
+		return DefaultOrderFile
+	}
+	return dbConfig.OrderFile
+}
+
+// hasOrderFile reports if the folder has an ordering manifest, which replaces sorting migrations by ID
+func hasOrderFile(dbConfig DBConfig) (bool, error) {
+	fsys, err := migrationsFS(dbConfig)
+	if err != nil {
+		return false, err
+	}
+	exists, err := fileExists(fsys, mainFolder(dbConfig), orderFileName(dbConfig))
+	if err != nil {
+		return false, fmt.Errorf("could not read %s: %w", orderFileName(dbConfig), err)
+	}
+	return exists, nil
+}
+
+// migrationIndex is the position of the migration with the ID in the order they are applied, -1 when there is none
+func migrationIndex(migrations []migration, migrationID string) int {
+	for i, migration := range migrations {
+		if migration.id == migrationID {
+			return i
+		}
+	}
+	return -1
+}
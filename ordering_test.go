@@ -0,0 +1,155 @@
+package migrationhandler_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	migrationhandler "github.com/jvfrodrigues/gorm-migration-handler"
+)
+
+func TestOrderFile(t *testing.T) {
+	tests := []struct {
+		name            string
+		orderFile       string
+		files           map[string]string
+		expectedError   error
+		expectedApplied []string
+	}{
+		{
+			name: "Test if migrations are applied in the order of the manifest",
+			files: map[string]string{
+				"migrations.order": "# hotfix 3 runs before 2\n1\n3\n\n2\n",
+			},
+			expectedApplied: []string{"1", "3", "2"},
+		},
+		{
+			name:      "Test if the name of the manifest can be changed",
+			orderFile: "apply.txt",
+			files: map[string]string{
+				"apply.txt": "1\n3\n2\n",
+			},
+			expectedApplied: []string{"1", "3", "2"},
+		},
+		{
+			name: "Test if migrations are sorted by ID without a manifest",
+			files: map[string]string{
+				"apply.txt": "1\n3\n2\n",
+			},
+			expectedError: errors.New("SQL logic error: no such table: ordering_hotfix (1)"),
+		},
+		{
+			name: "Test if it errors on a listed ID without a file",
+			files: map[string]string{
+				"migrations.order": "1\n3\n4\n2\n",
+			},
+			expectedError: errors.New("migration 4 is listed in migrations.order but has no file"),
+		},
+		{
+			name: "Test if it errors on a migration missing from the manifest",
+			files: map[string]string{
+				"migrations.order": "1\n3\n",
+			},
+			expectedError: errors.New("migration 2_second is missing from migrations.order"),
+		},
+		{
+			name: "Test if it errors on an ID listed twice",
+			files: map[string]string{
+				"migrations.order": "1\n3\n2\n3\n",
+			},
+			expectedError: errors.New("migration 3 is listed more than once in migrations.order"),
+		},
+	}
+	for i, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := tempDir(t)
+			defer func() {
+				_ = os.RemoveAll(dir)
+			}()
+			writeMigration(t, dir, "1_first_up.sql", "CREATE TABLE ordering_first (id integer);")
+			writeMigration(t, dir, "1_first_down.sql", "DROP TABLE ordering_first;")
+			writeMigration(t, dir, "2_second_up.sql", "INSERT INTO ordering_hotfix (id) VALUES (1);")
+			writeMigration(t, dir, "2_second_down.sql", "DELETE FROM ordering_hotfix;")
+			writeMigration(t, dir, "3_hotfix_up.sql", "CREATE TABLE ordering_hotfix (id integer);")
+			writeMigration(t, dir, "3_hotfix_down.sql", "DROP TABLE ordering_hotfix;")
+			for fileName, content := range tc.files {
+				writeMigration(t, dir, fileName, content)
+			}
+			applied, err := migrationhandler.RunMigrations(migrationhandler.DBConfig{
+				Dialector:            sqlite.Open(fmt.Sprintf("file:ordering_test_%d?mode=memory&cache=shared", i)),
+				MigrationsFolderPath: "./" + dir,
+				OrderFile:            tc.orderFile,
+			})
+			if err != nil || tc.expectedError != nil {
+				if err == nil || tc.expectedError == nil || err.Error() != tc.expectedError.Error() {
+					t.Errorf("expected: %+v, got: %+v", tc.expectedError, err)
+				}
+				return
+			}
+			ids := make([]string, 0, len(applied))
+			for _, migration := range applied {
+				ids = append(ids, migration.ID)
+			}
+			if !reflect.DeepEqual(ids, tc.expectedApplied) {
+				t.Errorf("expected: %+v, got: %+v", tc.expectedApplied, ids)
+			}
+		})
+	}
+}
+
+func TestOrderFileStatusAndBaseline(t *testing.T) {
+	dir := tempDir(t)
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+	writeMigration(t, dir, "1_first_up.sql", "CREATE TABLE ordering_first (id integer);")
+	writeMigration(t, dir, "1_first_down.sql", "DROP TABLE ordering_first;")
+	writeMigration(t, dir, "2_second_up.sql", "INSERT INTO ordering_hotfix (id) VALUES (1);")
+	writeMigration(t, dir, "2_second_down.sql", "DELETE FROM ordering_hotfix;")
+	writeMigration(t, dir, "3_hotfix_up.sql", "CREATE TABLE ordering_hotfix (id integer);")
+	writeMigration(t, dir, "3_hotfix_down.sql", "DROP TABLE ordering_hotfix;")
+	// the baseline squashes 2, the last migration of the manifest, so 3 is squashed even if its ID is greater
+	writeMigration(t, dir, "9_baseline_up.sql", "-- squashes: 2\nCREATE TABLE ordering_first (id integer);\nCREATE TABLE ordering_hotfix (id integer);")
+	writeMigration(t, dir, "9_baseline_down.sql", "DROP TABLE ordering_hotfix;\nDROP TABLE ordering_first;")
+	writeMigration(t, dir, "migrations.order", "1\n3\n2\n9\n")
+	dbConfig := migrationhandler.DBConfig{
+		Dialector:            sqlite.Open("file:ordering_baseline_test?mode=memory&cache=shared"),
+		MigrationsFolderPath: "./" + dir,
+	}
+	status, err := migrationhandler.MigrationStatus(dbConfig)
+	if err != nil {
+		t.Fatalf("expected: %+v, got: %+v", nil, err)
+	}
+	expectedPending := []string{"1_first pending", "3_hotfix pending", "2_second pending", "9_baseline pending"}
+	if fmt.Sprint(statusLines(status)) != fmt.Sprint(expectedPending) {
+		t.Errorf("expected: %+v, got: %+v", expectedPending, statusLines(status))
+	}
+	_, err = migrationhandler.RunMigrations(dbConfig)
+	if err != nil {
+		t.Fatalf("expected: %+v, got: %+v", nil, err)
+	}
+	status, err = migrationhandler.MigrationStatus(dbConfig)
+	if err != nil {
+		t.Fatalf("expected: %+v, got: %+v", nil, err)
+	}
+	expectedApplied := []string{"1_first applied", "3_hotfix applied", "2_second applied", "9_baseline applied"}
+	if fmt.Sprint(statusLines(status)) != fmt.Sprint(expectedApplied) {
+		t.Errorf("expected: %+v, got: %+v", expectedApplied, statusLines(status))
+	}
+}
+
+// statusLines describes every migration of the status as its ID, name and if it is applied
+func statusLines(status []migrationhandler.MigrationInfo) []string {
+	lines := make([]string, 0, len(status))
+	for _, info := range status {
+		state := "pending"
+		if info.Applied {
+			state = "applied"
+		}
+		lines = append(lines, info.ID+"_"+info.Name+" "+state)
+	}
+	return lines
+}
@@ -0,0 +1,24 @@
+package migrationhandler
+
+import "fmt"
+
+// Ping connects to the database and pings it without reading or running any migration, a preflight check of the
+// connection. The connection opened from Dialector is closed afterwards, the pool of DB is left open
+func Ping(dbConfig DBConfig) error {
+	db, err := newDatabase(dbConfig)
+	if err != nil {
+		return fmt.Errorf("%w, can not ping: %w", ErrConnectionFailed, err)
+	}
+	sqlDB, err := db.Db.DB()
+	if err != nil {
+		return fmt.Errorf("%w, can not ping: %w", ErrConnectionFailed, err)
+	}
+	if dbConfig.DB == nil {
+		defer sqlDB.Close()
+	}
+	err = sqlDB.PingContext(db.Db.Statement.Context)
+	if err != nil {
+		return fmt.Errorf("%w, can not ping: %w", ErrConnectionFailed, err)
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package migrationhandler_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	migrationhandler "github.com/jvfrodrigues/gorm-migration-handler"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestPing(t *testing.T) {
+	tests := []struct {
+		name          string
+		failures      int
+		sharedDB      bool
+		expectedError error
+	}{
+		{
+			name:          "Test if it pings the database of the dialector",
+			expectedError: nil,
+		},
+		{
+			name:          "Test if it pings the database of an open connection and leaves it open",
+			sharedDB:      true,
+			expectedError: nil,
+		},
+		{
+			name:          "Test if it errors when it can not connect",
+			failures:      1,
+			expectedError: errors.New("connection to database failed, can not ping: connection refused"),
+		},
+	}
+	for i, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dbConfig := migrationhandler.DBConfig{
+				Dialector: &startingDialector{
+					Dialector: sqlite.Open(fmt.Sprintf("file:ping_test_%d?mode=memory&cache=shared", i)),
+					failures:  tc.failures,
+				},
+			}
+			if tc.sharedDB {
+				db, err := gorm.Open(dbConfig.Dialector, &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+				if err != nil {
+					t.Fatalf("test error: %v", err)
+				}
+				dbConfig.DB = db
+			}
+			err := migrationhandler.Ping(dbConfig)
+			if err != nil || tc.expectedError != nil {
+				if err == nil || tc.expectedError == nil || err.Error() != tc.expectedError.Error() {
+					t.Errorf("expected: %+v, got: %+v", tc.expectedError, err)
+				}
+			}
+			if tc.sharedDB {
+				if err := dbConfig.DB.Exec("SELECT 1").Error; err != nil {
+					t.Errorf("expected the connection to stay open, got: %+v", err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,183 @@
+package migrationhandler
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Repair gets DB info and gets all migrations from given folder to compare them with the migrations table,
+// returning an error listing the IDs recorded without a file and the pending migrations whose tables already
+// exist, which look applied by hand. IDs squashed by an applied baseline are expected to have no file.
+// With RepairRecords set the table is changed to match the folder instead, removing the records without a
+// file and recording the migrations that look applied, without running them
+func Repair(dbConfig DBConfig) error {
+	db, err := newDatabase(dbConfig)
+	if err != nil {
+		return fmt.Errorf("%w, can not repair migrations: %w", ErrConnectionFailed, err)
+	}
+	migrations, err := getMigrations(dbConfig)
+	if err != nil {
+		return err
+	}
+	options := managerOptions(dbConfig)
+	if dbConfig.RepairRecords {
+		release, err := lockMigrations(db, dbConfig, options)
+		if err != nil {
+			return err
+		}
+		defer release()
+	}
+	applied, err := getAppliedMigrations(db, options)
+	if err != nil {
+		return err
+	}
+	files := make(map[string]bool, len(migrations))
+	squashed := ""
+	lookApplied := make([]migration, 0)
+	for _, migration := range migrations {
+		files[migration.id] = true
+		if _, ok := applied[migration.id]; ok {
+			if migration.squashes > squashed {
+				squashed = migration.squashes
+			}
+			continue
+		}
+		err = migration.loadUp()
+		if err != nil {
+			return err
+		}
+		if appearsApplied(db.Db, migration) {
+			lookApplied = append(lookApplied, migration)
+		}
+	}
+	orphaned := make([]string, 0)
+	for id := range applied {
+		if !files[id] && (squashed == "" || id > squashed) {
+			orphaned = append(orphaned, id)
+		}
+	}
+	sort.Strings(orphaned)
+	logger := getLogger(dbConfig)
+	if len(orphaned) == 0 && len(lookApplied) == 0 {
+		logger.Info("No differences found between the migrations table and the migrations folder")
+		return nil
+	}
+	if !dbConfig.RepairRecords {
+		problems := make([]error, 0, len(orphaned)+len(lookApplied))
+		for _, id := range orphaned {
+			problems = append(problems, fmt.Errorf("migration %s is recorded but has no file", id))
+		}
+		for _, migration := range lookApplied {
+			problems = append(problems, fmt.Errorf("migration %s_%s is not recorded but its tables already exist", migration.id, migration.name))
+		}
+		return errors.Join(problems...)
+	}
+	err = removeRecords(db.Db, options, orphaned)
+	if err != nil {
+		return fmt.Errorf("could not remove records without a file: %w", err)
+	}
+	for _, id := range orphaned {
+		logger.Info("Removed the record of migration %s as it has no file", id)
+	}
+	err = recordMigrations(db.Db, options, lookApplied)
+	if err != nil {
+		return fmt.Errorf("could not record migrations that look applied: %w", err)
+	}
+	for _, migration := range lookApplied {
+		logger.Info("Recorded migration '%s_%s' as its tables already exist", migration.id, migration.name)
+	}
+	return nil
+}
+
+// appearsApplied tells if every table created by the up file of a migration already exists, migrations
+// creating no tables can not be told apart and never appear applied
+func appearsApplied(db *gorm.DB, migration migration) bool {
+	tables := make([]string, 0)
+	for _, statement := range splitStatements(migration.migrationSQL) {
+		if match := createTableFilter.FindStringSubmatch(strings.TrimSpace(statement)); match != nil {
+			tables = append(tables, strings.Trim(match[1], "`\"[]"))
+		}
+	}
+	if len(tables) == 0 {
+		return false
+	}
+	for _, table := range tables {
+		if !db.Migrator().HasTable(table) {
+			return false
+		}
+	}
+	return true
+}
+
+// removeRecords deletes the given IDs from the migrations table and the records table
+func removeRecords(db *gorm.DB, options *gormigrate.Options, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Table(options.TableName).
+			Where(clause.IN{Column: clause.Column{Name: options.IDColumnName}, Values: toValues(ids)}).
+			Delete(map[string]interface{}{}).Error
+		if err != nil {
+			return err
+		}
+		if !tx.Migrator().HasTable(recordsTableName(options)) {
+			return nil
+		}
+		return tx.Table(recordsTableName(options)).Where("id IN ?", ids).Delete(&migrationRecord{}).Error
+	})
+}
+
+// recordMigrations marks migrations as applied without running them, letting gormigrate create the
+// migrations table when it is missing
+func recordMigrations(db *gorm.DB, options *gormigrate.Options, migrations []migration) error {
+	if len(migrations) == 0 {
+		return nil
+	}
+	err := createTableSchema(db, options)
+	if err != nil {
+		return err
+	}
+	recordOptions := *options
+	recordOptions.ValidateUnknownMigrations = false
+	gormMigrations := make([]*gormigrate.Migration, 0, len(migrations))
+	for _, migration := range migrations {
+		gormMigrations = append(gormMigrations, &gormigrate.Migration{
+			ID: migration.id,
+			Migrate: func(tx *gorm.DB) error {
+				return nil
+			},
+		})
+	}
+	err = gormigrate.New(db, &recordOptions, gormMigrations).Migrate()
+	if err != nil {
+		return err
+	}
+	err = db.Table(recordsTableName(options)).AutoMigrate(&migrationRecord{})
+	if err != nil {
+		return err
+	}
+	for _, migration := range migrations {
+		record := migrationRecord{ID: migration.id, AppliedAt: time.Now(), Checksum: migration.fileChecksum()}
+		err = db.Table(recordsTableName(options)).Create(&record).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func toValues(ids []string) []interface{} {
+	values := make([]interface{}, 0, len(ids))
+	for _, id := range ids {
+		values = append(values, id)
+	}
+	return values
+}
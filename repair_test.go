@@ -0,0 +1,102 @@
+package migrationhandler_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	migrationhandler "github.com/jvfrodrigues/gorm-migration-handler"
+	"gorm.io/gorm"
+)
+
+func TestRepair(t *testing.T) {
+	tests := []struct {
+		name            string
+		repairRecords   bool
+		drift           bool
+		expectedError   error
+		expectedApplied []string
+	}{
+		{
+			name:            "Test if a folder matching the table has nothing to repair",
+			expectedApplied: []string{"1", "2"},
+		},
+		{
+			name:  "Test if differences are only reported by default",
+			drift: true,
+			expectedError: errors.Join(
+				errors.New("migration 2 is recorded but has no file"),
+				errors.New("migration 3_orders is not recorded but its tables already exist"),
+			),
+			expectedApplied: []string{"1", "2"},
+		},
+		{
+			name:            "Test if records are repaired when asked to",
+			repairRecords:   true,
+			drift:           true,
+			expectedApplied: []string{"1", "3"},
+		},
+	}
+	for i, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := tempDir(t)
+			defer func() {
+				_ = os.RemoveAll(dir)
+			}()
+			dbConfig := migrationhandler.DBConfig{
+				Dialector:            sqlite.Open(fmt.Sprintf("file:repair_test_%d?mode=memory&cache=shared", i)),
+				MigrationsFolderPath: "./" + dir,
+				RepairRecords:        tc.repairRecords,
+			}
+			// keeps the shared in memory database alive between calls
+			db, err := gorm.Open(dbConfig.Dialector, &gorm.Config{})
+			if err != nil {
+				t.Fatalf("test error: %v", err)
+			}
+			sqlDB, _ := db.DB()
+			defer sqlDB.Close()
+			writeMigration(t, dir, "1_users_up.sql", "CREATE TABLE users (id integer);")
+			writeMigration(t, dir, "1_users_down.sql", "DROP TABLE users;")
+			writeMigration(t, dir, "2_posts_up.sql", "CREATE TABLE posts (id integer);")
+			writeMigration(t, dir, "2_posts_down.sql", "DROP TABLE posts;")
+			_, err = migrationhandler.RunMigrations(dbConfig)
+			if err != nil {
+				t.Fatalf("test error: %v", err)
+			}
+			if tc.drift {
+				for _, fileName := range []string{"2_posts_up.sql", "2_posts_down.sql"} {
+					if err := os.Remove(filepath.Join(dir, fileName)); err != nil {
+						t.Fatalf("test error: %v", err)
+					}
+				}
+				writeMigration(t, dir, "3_orders_up.sql", "CREATE TABLE orders (id integer);")
+				writeMigration(t, dir, "3_orders_down.sql", "DROP TABLE orders;")
+				if err := db.Exec("CREATE TABLE orders (id integer)").Error; err != nil {
+					t.Fatalf("test error: %v", err)
+				}
+			}
+			err = migrationhandler.Repair(dbConfig)
+			if err != nil || tc.expectedError != nil {
+				if err == nil || tc.expectedError == nil || err.Error() != tc.expectedError.Error() {
+					t.Errorf("expected: %+v, got: %+v", tc.expectedError, err)
+				}
+			}
+			status, err := migrationhandler.MigrationStatus(dbConfig)
+			if err != nil {
+				t.Fatalf("test error: %v", err)
+			}
+			applied := make([]string, 0)
+			for _, info := range status {
+				if info.Applied {
+					applied = append(applied, info.ID)
+				}
+			}
+			if fmt.Sprint(applied) != fmt.Sprint(tc.expectedApplied) {
+				t.Errorf("expected applied: %+v, got: %+v", tc.expectedApplied, applied)
+			}
+		})
+	}
+}
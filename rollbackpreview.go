@@ -0,0 +1,80 @@
+package migrationhandler
+
+import "fmt"
+
+// PreviewRollback gets DB info and gets all migrations from given folder to list the migrations RollbackN would
+// roll back, newest first, without rolling back anything. It fails like RollbackN when fewer than n migrations
+// are applied or any of them can not be rolled back
+func PreviewRollback(dbConfig DBConfig, n int) ([]MigrationInfo, error) {
+	if n <= 0 {
+		return nil, fmt.Errorf("invalid number of migrations to rollback %d", n)
+	}
+	migrations, applied, err := appliedInOrder(dbConfig)
+	if err != nil {
+		return nil, err
+	}
+	if len(applied) < n {
+		return nil, fmt.Errorf("can not rollback %d migrations, only %d are applied", n, len(applied))
+	}
+	return newestFirst(migrations, applied[len(applied)-n:], fmt.Sprintf("rollback %d migrations", n))
+}
+
+// PreviewRollbackTo gets DB info and gets all migrations from given folder to list the migrations RollbackTo would
+// roll back, the applied ones after migrationID, newest first, without rolling back anything
+func PreviewRollbackTo(dbConfig DBConfig, migrationID string) ([]MigrationInfo, error) {
+	migrations, applied, err := appliedInOrder(dbConfig)
+	if err != nil {
+		return nil, err
+	}
+	target := migrationIndex(migrations, migrationID)
+	if target < 0 {
+		return nil, fmt.Errorf("%w: %s", ErrMigrationNotFound, migrationID)
+	}
+	after := make([]MigrationInfo, 0)
+	for _, info := range applied {
+		if migrationIndex(migrations, info.ID) > target {
+			after = append(after, info)
+		}
+	}
+	return newestFirst(migrations, after, "rollback to migration "+migrationID)
+}
+
+// appliedInOrder returns the migrations of the folder and the ones of them that are applied, in the order they run
+func appliedInOrder(dbConfig DBConfig) ([]migration, []MigrationInfo, error) {
+	db, err := newDatabase(dbConfig)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w, can not preview rollback: %w", ErrConnectionFailed, err)
+	}
+	migrations, err := getMigrations(dbConfig)
+	if err != nil {
+		return nil, nil, err
+	}
+	appliedAt, err := getAppliedMigrations(db, managerOptions(dbConfig))
+	if err != nil {
+		return nil, nil, err
+	}
+	applied := make([]MigrationInfo, 0)
+	for _, migration := range migrations {
+		if at, ok := appliedAt[migration.id]; ok {
+			applied = append(applied, MigrationInfo{ID: migration.id, Name: migration.name, Applied: true, AppliedAt: at})
+		}
+	}
+	return migrations, applied, nil
+}
+
+// newestFirst reverses the migrations to roll back, checking each of them can be rolled back as part of action
+func newestFirst(migrations []migration, toRollback []MigrationInfo, action string) ([]MigrationInfo, error) {
+	preview := make([]MigrationInfo, 0, len(toRollback))
+	for i := len(toRollback) - 1; i >= 0; i-- {
+		for _, migration := range migrations {
+			if migration.id != toRollback[i].ID {
+				continue
+			}
+			if err := migration.checkRollback(action); err != nil {
+				return nil, err
+			}
+		}
+		preview = append(preview, toRollback[i])
+	}
+	return preview, nil
+}
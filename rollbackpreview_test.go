@@ -0,0 +1,111 @@
+package migrationhandler_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	migrationhandler "github.com/jvfrodrigues/gorm-migration-handler"
+)
+
+func TestPreviewRollback(t *testing.T) {
+	tests := []struct {
+		name          string
+		preview       func(dbConfig migrationhandler.DBConfig) ([]migrationhandler.MigrationInfo, error)
+		expectedError error
+		expectedIDs   []string
+	}{
+		{
+			name: "Test if the last n applied migrations are listed newest first",
+			preview: func(dbConfig migrationhandler.DBConfig) ([]migrationhandler.MigrationInfo, error) {
+				return migrationhandler.PreviewRollback(dbConfig, 2)
+			},
+			expectedIDs: []string{"3", "2"},
+		},
+		{
+			name: "Test if it errors when fewer migrations are applied",
+			preview: func(dbConfig migrationhandler.DBConfig) ([]migrationhandler.MigrationInfo, error) {
+				return migrationhandler.PreviewRollback(dbConfig, 4)
+			},
+			expectedError: errors.New("can not rollback 4 migrations, only 3 are applied"),
+		},
+		{
+			name: "Test if it errors on migrations that can not be rolled back",
+			preview: func(dbConfig migrationhandler.DBConfig) ([]migrationhandler.MigrationInfo, error) {
+				return migrationhandler.PreviewRollback(dbConfig, 3)
+			},
+			expectedError: errors.New("migration 1_first is irreversible, can not rollback 3 migrations"),
+		},
+		{
+			name: "Test if the migrations after the target are listed newest first",
+			preview: func(dbConfig migrationhandler.DBConfig) ([]migrationhandler.MigrationInfo, error) {
+				return migrationhandler.PreviewRollbackTo(dbConfig, "1")
+			},
+			expectedIDs: []string{"3", "2"},
+		},
+		{
+			name: "Test if nothing is listed when rolling back to the last migration",
+			preview: func(dbConfig migrationhandler.DBConfig) ([]migrationhandler.MigrationInfo, error) {
+				return migrationhandler.PreviewRollbackTo(dbConfig, "3")
+			},
+			expectedIDs: []string{},
+		},
+		{
+			name: "Test if it errors on non existing migration ID",
+			preview: func(dbConfig migrationhandler.DBConfig) ([]migrationhandler.MigrationInfo, error) {
+				return migrationhandler.PreviewRollbackTo(dbConfig, "9")
+			},
+			expectedError: errors.New("migration not found: 9"),
+		},
+	}
+	for i, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := tempDir(t)
+			defer func() {
+				_ = os.RemoveAll(dir)
+			}()
+			dbConfig := migrationhandler.DBConfig{
+				Dialector:            sqlite.Open(fmt.Sprintf("file:preview_rollback_test_%d?mode=memory&cache=shared", i)),
+				MigrationsFolderPath: "./" + dir,
+			}
+			writeMigration(t, dir, "1_first_up.sql", "CREATE TABLE preview_first (id integer);")
+			writeMigration(t, dir, "1_first_down.sql", "-- irreversible\n")
+			writeMigration(t, dir, "2_second_up.sql", "CREATE TABLE preview_second (id integer);")
+			writeMigration(t, dir, "2_second_down.sql", "DROP TABLE preview_second;")
+			writeMigration(t, dir, "3_third_up.sql", "CREATE TABLE preview_third (id integer);")
+			writeMigration(t, dir, "3_third_down.sql", "DROP TABLE preview_third;")
+			_, err := migrationhandler.RunMigrations(dbConfig)
+			if err != nil {
+				t.Fatalf("test error: %v", err)
+			}
+			preview, err := tc.preview(dbConfig)
+			if err != nil || tc.expectedError != nil {
+				if err == nil || tc.expectedError == nil || err.Error() != tc.expectedError.Error() {
+					t.Errorf("expected: %+v, got: %+v", tc.expectedError, err)
+				}
+				return
+			}
+			ids := make([]string, 0, len(preview))
+			for _, info := range preview {
+				ids = append(ids, info.ID)
+				if !info.Applied || info.AppliedAt.IsZero() {
+					t.Errorf("expected migration %s to be applied, got: %+v", info.ID, info)
+				}
+			}
+			if fmt.Sprint(ids) != fmt.Sprint(tc.expectedIDs) {
+				t.Errorf("expected: %+v, got: %+v", tc.expectedIDs, ids)
+			}
+			status, err := migrationhandler.MigrationStatus(dbConfig)
+			if err != nil {
+				t.Fatalf("test error: %v", err)
+			}
+			for _, info := range status {
+				if !info.Applied {
+					t.Errorf("expected nothing to be rolled back, got: %+v", info)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package migrationhandler
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var seedFilter = regexp.MustCompile(`^\d+.*_seed.sql$`)
+
+// seed is a data file named <id>_<name>_seed.sql kept alongside the migrations
+type seed struct {
+	id      string
+	name    string
+	seedSQL string
+}
+
+// seedRecord tracks the last run of a seed in the <TableName>_seeds table
+type seedRecord struct {
+	ID       string `gorm:"primaryKey;size:255"`
+	RunAt    time.Time
+	Checksum string `gorm:"size:64"`
+}
+
+// RunSeeds requires the dbConfig and runs every seed file of the migrations folder, named
+// <id>_<name>_seed.sql, sorted by their ID. Seeds run again on every call so they must be safe to
+// re-run, use INSERT ... ON CONFLICT DO NOTHING or the equivalent of your database when writing them.
+// Their last run is tracked in the <TableName>_seeds table
+func RunSeeds(dbConfig DBConfig) ([]AppliedMigration, error) {
+	db, err := newDatabase(dbConfig)
+	if err != nil {
+		return nil, fmt.Errorf("%w, can not run seeds: %w", ErrConnectionFailed, err)
+	}
+	seeds, err := getSeeds(dbConfig)
+	if err != nil {
+		return nil, err
+	}
+	if len(seeds) <= 0 {
+		return nil, errors.New("no seeds to run")
+	}
+	tableName := managerOptions(dbConfig).TableName + "_seeds"
+	err = db.Db.Table(tableName).AutoMigrate(&seedRecord{})
+	if err != nil {
+		return nil, err
+	}
+	applied := make([]AppliedMigration, 0, len(seeds))
+	for _, seed := range seeds {
+		start := time.Now()
+		err = db.Db.Transaction(func(tx *gorm.DB) error {
+			err := execStatements(tx, seed.seedSQL)
+			if err != nil {
+				return err
+			}
+			record := seedRecord{ID: seed.id, RunAt: time.Now(), Checksum: checksum(seed.seedSQL)}
+			return tx.Table(tableName).Clauses(clause.OnConflict{UpdateAll: true}).Create(&record).Error
+		})
+		if err != nil {
+			return applied, err
+		}
+		applied = append(applied, AppliedMigration{ID: seed.id, Name: seed.name, Duration: time.Since(start)})
+	}
+	getLogger(dbConfig).Info("Seeds successful")
+	return applied, nil
+}
+
+// getSeeds gets all seeds from the configured folder sorted by their ID
+func getSeeds(dbConfig DBConfig) ([]seed, error) {
+	fsys, err := migrationsFS(dbConfig)
+	if err != nil {
+		return nil, err
+	}
+	folderPath := dbConfig.MigrationsFolderPath
+	files, err := readDir(fsys, folderPath)
+	if err != nil {
+		return nil, err
+	}
+	seeds := make([]seed, 0)
+	for _, file := range files {
+		if file.IsDir() || !seedFilter.MatchString(file.Name()) {
+			continue
+		}
+		content, err := readFile(fsys, folderPath, file.Name())
+		if err != nil {
+			return nil, err
+		}
+		id, name, _ := strings.Cut(strings.TrimSuffix(file.Name(), "_seed.sql"), "_")
+		seeds = append(seeds, seed{id: id, name: name, seedSQL: string(content)})
+	}
+	sort.Slice(seeds, func(i, j int) bool {
+		return seeds[i].id < seeds[j].id
+	})
+	return seeds, nil
+}
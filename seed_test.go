@@ -0,0 +1,81 @@
+package migrationhandler_test
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	migrationhandler "github.com/jvfrodrigues/gorm-migration-handler"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestRunSeeds(t *testing.T) {
+	dialector := sqlite.Open("file:seed_test?mode=memory&cache=shared")
+	db, err := gorm.Open(dialector, &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("test error: %v", err)
+	}
+	dir := tempDir(t)
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+	dbConfig := migrationhandler.DBConfig{
+		Dialector:            dialector,
+		MigrationsFolderPath: "./" + dir,
+	}
+	writeMigration(t, dir, "1_roles_up.sql", "CREATE TABLE seed_roles (id integer primary key, name text);")
+	writeMigration(t, dir, "1_roles_down.sql", "DROP TABLE seed_roles;")
+	writeMigration(t, dir, "2_roles_seed.sql", "INSERT INTO seed_roles (id, name) VALUES (2, 'user') ON CONFLICT DO NOTHING;")
+	writeMigration(t, dir, "1_roles_seed.sql", "INSERT INTO seed_roles (id, name) VALUES (1, 'admin') ON CONFLICT DO NOTHING;")
+	_, err = migrationhandler.RunMigrations(dbConfig)
+	if err != nil {
+		t.Fatalf("test error: %v", err)
+	}
+	tests := []struct {
+		name            string
+		expectedApplied []migrationhandler.AppliedMigration
+		expectedRows    int64
+	}{
+		{
+			name: "Test if seeds run sorted by ID",
+			expectedApplied: []migrationhandler.AppliedMigration{
+				{ID: "1", Name: "roles"},
+				{ID: "2", Name: "roles"},
+			},
+			expectedRows: 2,
+		},
+		{
+			name: "Test if seeds can be run again",
+			expectedApplied: []migrationhandler.AppliedMigration{
+				{ID: "1", Name: "roles"},
+				{ID: "2", Name: "roles"},
+			},
+			expectedRows: 2,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			applied, err := migrationhandler.RunSeeds(dbConfig)
+			if err != nil {
+				t.Fatalf("expected: %+v, got: %+v", nil, err)
+			}
+			if !reflect.DeepEqual(withoutDurations(applied), tc.expectedApplied) {
+				t.Errorf("expected: %+v, got: %+v", tc.expectedApplied, applied)
+			}
+			var rows int64
+			db.Table("seed_roles").Count(&rows)
+			if rows != tc.expectedRows {
+				t.Errorf("expected: %+v rows, got: %+v", tc.expectedRows, rows)
+			}
+		})
+	}
+	if !db.Migrator().HasTable("migrations_seeds") {
+		t.Errorf("expected seeds to be tracked in %v", "migrations_seeds")
+	}
+	_, err = migrationhandler.LoadMigrations("./" + dir)
+	if err != nil {
+		t.Errorf("expected seeds to not be loaded as migrations, got: %+v", err)
+	}
+}
@@ -0,0 +1,34 @@
+package migrationhandler
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// seedSchema runs SeedSchemaSQL on a database where migrations never ran, known by the migrations table not
+// existing yet, so the base schema is created once before the first managed migration
+func (m *migrationManager) seedSchema(dbConfig DBConfig) error {
+	if dbConfig.SeedSchemaSQL == "" || m.db.Db.Migrator().HasTable(m.options.TableName) {
+		return nil
+	}
+	seedSQL := m.transformSQL(dbConfig.SeedSchemaSQL)
+	if dbConfig.DryRun {
+		m.logger.Info("Seed schema would run:\n%s", seedSQL)
+		return nil
+	}
+	run := func(tx *gorm.DB) error {
+		return execStatements(tx, seedSQL)
+	}
+	var err error
+	if m.transactionalDDL {
+		err = m.db.Db.Transaction(run)
+	} else {
+		err = run(m.db.Db)
+	}
+	if err != nil {
+		return fmt.Errorf("seed schema failed: %w", err)
+	}
+	m.logger.Info("Seed schema applied")
+	return nil
+}
@@ -0,0 +1,77 @@
+package migrationhandler_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	migrationhandler "github.com/jvfrodrigues/gorm-migration-handler"
+	"gorm.io/gorm"
+)
+
+func TestRunMigrationsSeedSchema(t *testing.T) {
+	tests := []struct {
+		name          string
+		seedSchemaSQL string
+		expectedError error
+		expectedRows  int64
+	}{
+		{
+			name:          "Test if the seed schema runs once before the first migration",
+			seedSchemaSQL: "CREATE TABLE legacy_users (id integer); INSERT INTO legacy_users (id) VALUES (1);",
+			expectedError: nil,
+			expectedRows:  2,
+		},
+		{
+			name:          "Test if a failing seed schema stops the run",
+			seedSchemaSQL: "CREATE TABLE;",
+			expectedError: errors.New("seed schema failed: SQL logic error: incomplete input (1)"),
+			expectedRows:  0,
+		},
+	}
+	for i, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := tempDir(t)
+			defer func() {
+				_ = os.RemoveAll(dir)
+			}()
+			dsn := fmt.Sprintf("file:seed_schema_test_%v?mode=memory&cache=shared", i)
+			db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{})
+			if err != nil {
+				t.Fatalf("test error: %v", err)
+			}
+			dbConfig := migrationhandler.DBConfig{
+				Dialector:            sqlite.Open(dsn),
+				MigrationsFolderPath: "./" + dir,
+				SeedSchemaSQL:        tc.seedSchemaSQL,
+			}
+			writeMigration(t, dir, "1_legacy_up.sql", "INSERT INTO legacy_users (id) VALUES (2);")
+			writeMigration(t, dir, "1_legacy_down.sql", "DELETE FROM legacy_users WHERE id = 2;")
+			_, err = migrationhandler.RunMigrations(dbConfig)
+			if err != nil || tc.expectedError != nil {
+				if err == nil || tc.expectedError == nil || err.Error() != tc.expectedError.Error() {
+					t.Errorf("expected: %+v, got: %+v", tc.expectedError, err)
+				}
+			}
+			if err != nil {
+				return
+			}
+			writeMigration(t, dir, "2_more_up.sql", "SELECT 1;")
+			writeMigration(t, dir, "2_more_down.sql", "SELECT 1;")
+			_, err = migrationhandler.RunMigrations(dbConfig)
+			if err != nil {
+				t.Fatalf("expected: %+v, got: %+v", nil, err)
+			}
+			var rows int64
+			err = db.Table("legacy_users").Count(&rows).Error
+			if err != nil {
+				t.Fatalf("test error: %v", err)
+			}
+			if rows != tc.expectedRows {
+				t.Errorf("expected: %+v, got: %+v", tc.expectedRows, rows)
+			}
+		})
+	}
+}
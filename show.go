@@ -0,0 +1,24 @@
+package migrationhandler
+
+import "fmt"
+
+// ShowMigration requires the dbConfig and returns the up and down SQL of the migration with the given ID as it
+// would run, after ExpandEnv and SQLTransform, without connecting to the database
+func ShowMigration(dbConfig DBConfig, migrationID string) (string, string, error) {
+	migrations, err := getMigrations(dbConfig)
+	if err != nil {
+		return "", "", err
+	}
+	for _, migration := range migrations {
+		if migration.id != migrationID {
+			continue
+		}
+		err = migration.load()
+		if err != nil {
+			return "", "", err
+		}
+		transform := getSQLTransform(dbConfig)
+		return migration.describeUp(transform), migration.describeDown(transform), nil
+	}
+	return "", "", fmt.Errorf("%w: %s", ErrMigrationNotFound, migrationID)
+}
@@ -0,0 +1,66 @@
+package migrationhandler_test
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	migrationhandler "github.com/jvfrodrigues/gorm-migration-handler"
+)
+
+func TestShowMigration(t *testing.T) {
+	tests := []struct {
+		name          string
+		migrationID   string
+		sqlTransform  func(sql string) string
+		expectedUp    string
+		expectedDown  string
+		expectedError error
+	}{
+		{
+			name:         "Test if the SQL of the migration is returned",
+			migrationID:  "2",
+			expectedUp:   "CREATE TABLE orders (id integer);",
+			expectedDown: "DROP TABLE orders;",
+		},
+		{
+			name:        "Test if the SQL is transformed",
+			migrationID: "2",
+			sqlTransform: func(sql string) string {
+				return strings.ReplaceAll(sql, "orders", "tenant.orders")
+			},
+			expectedUp:   "CREATE TABLE tenant.orders (id integer);",
+			expectedDown: "DROP TABLE tenant.orders;",
+		},
+		{
+			name:          "Test if it errors on non existing migration ID",
+			migrationID:   "9",
+			expectedError: errors.New("migration not found: 9"),
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := tempDir(t)
+			defer func() {
+				_ = os.RemoveAll(dir)
+			}()
+			writeMigration(t, dir, "1_users_up.sql", "CREATE TABLE users (id integer);")
+			writeMigration(t, dir, "1_users_down.sql", "DROP TABLE users;")
+			writeMigration(t, dir, "2_orders_up.sql", "CREATE TABLE orders (id integer);")
+			writeMigration(t, dir, "2_orders_down.sql", "DROP TABLE orders;")
+			up, down, err := migrationhandler.ShowMigration(migrationhandler.DBConfig{
+				MigrationsFolderPath: "./" + dir,
+				SQLTransform:         tc.sqlTransform,
+			}, tc.migrationID)
+			if err != nil || tc.expectedError != nil {
+				if err == nil || tc.expectedError == nil || err.Error() != tc.expectedError.Error() {
+					t.Errorf("expected: %+v, got: %+v", tc.expectedError, err)
+				}
+			}
+			if up != tc.expectedUp || down != tc.expectedDown {
+				t.Errorf("expected: %q %q, got: %q %q", tc.expectedUp, tc.expectedDown, up, down)
+			}
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package migrationhandler
+
+import (
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// Squash applies every migration of the folder to a clean database, dumps the resulting schema and writes it
+// as a single baseline migration named newName. Its up file starts with a "-- squashes: <ID>" line naming the
+// last migration it replaces, and its down file drops every table of the schema. The squashed files are kept:
+// databases with none of them applied run only the baseline, which marks them as applied, while databases
+// with any of them applied run the ones still pending and then mark the baseline as applied without running it.
+//
+// Already migrated environments need no changes to pick up the baseline, but the squashed files can only be
+// deleted once every one of them ran up to the baseline, otherwise the migrations they are missing are lost
+func Squash(dbConfig DBConfig, newName string) error {
+	err := validateMigrationName(dbConfig, newName)
+	if err != nil {
+		return err
+	}
+	db, err := newDatabase(dbConfig)
+	if err != nil {
+		return fmt.Errorf("%w, can not squash migrations: %w", ErrConnectionFailed, err)
+	}
+	applied, err := getAppliedMigrations(db, managerOptions(dbConfig))
+	if err != nil {
+		return err
+	}
+	if len(applied) > 0 {
+		return errors.New("database has applied migrations, squash needs a clean database")
+	}
+	migrations, err := getMigrations(dbConfig)
+	if err != nil {
+		return err
+	}
+	if len(migrations) <= 0 {
+		return fmt.Errorf("%w to squash", ErrNoMigrations)
+	}
+	dbConfig.DryRun = false
+	_, err = RunMigrations(dbConfig)
+	if err != nil {
+		return err
+	}
+	dumpConfig := dbConfig
+	dumpConfig.SchemaDumpPath = ""
+	schema, err := DumpSchema(dumpConfig)
+	if err != nil {
+		return err
+	}
+	baseline := newMigration(dbConfig, newName)
+	baseline.migrationSQL = fmt.Sprintf("-- %s: %s\n%s", squashesDirective, migrations[len(migrations)-1].id, schema)
+	baseline.rollbackSQL = dropTablesSQL(schema)
+	_, _, err = writeMigration(dbConfig, baseline)
+	return err
+}
+
+// dropTablesSQL drops every table created by the given schema, in reverse order
+func dropTablesSQL(schema string) string {
+	statements := splitStatements(schema)
+	lines := ""
+	for i := len(statements) - 1; i >= 0; i-- {
+		if match := createTableFilter.FindStringSubmatch(statements[i]); match != nil {
+			lines += fmt.Sprintf("DROP TABLE IF EXISTS %s;\n", match[1])
+		}
+	}
+	return lines
+}
+
+// resolveBaselines sets how the baselines written by Squash run, starting from the latest as it can squash
+// earlier ones. When none of its squashed migrations is applied they are left out of the run for the baseline
+// to mark them as applied, otherwise the baseline is only marked as applied after them
+func (m *migrationManager) resolveBaselines() error {
+	hasBaseline := false
+	for _, migration := range m.migrations {
+		hasBaseline = hasBaseline || migration.squashes != ""
+	}
+	if !hasBaseline {
+		return nil
+	}
+	applied, err := getAppliedMigrations(m.db, m.options)
+	if err != nil {
+		return err
+	}
+	dropped := make(map[string]bool)
+	for i := len(m.migrations) - 1; i >= 0; i-- {
+		baseline := &m.migrations[i]
+		if _, ok := applied[baseline.id]; ok || baseline.squashes == "" || dropped[baseline.id] {
+			continue
+		}
+		for appliedID := range applied {
+			if m.squashedBy(*baseline, appliedID) {
+				baseline.markOnly = true
+				break
+			}
+		}
+		if baseline.markOnly {
+			continue
+		}
+		for _, migration := range m.migrations[:i] {
+			if m.squashedBy(*baseline, migration.id) {
+				dropped[migration.id] = true
+				baseline.squashed = append(baseline.squashed, migration.id)
+			}
+		}
+	}
+	migrations := make([]migration, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		if !dropped[migration.id] {
+			migrations = append(migrations, migration)
+		}
+	}
+	m.migrations = migrations
+	return nil
+}
+
+// squashedBy reports if the migration with the ID is replaced by the baseline, running before it up to the
+// migration it squashes. IDs without a file, like the ones of squashed files already deleted, are compared as text
+func (m *migrationManager) squashedBy(baseline migration, migrationID string) bool {
+	last, position := migrationIndex(m.migrations, baseline.squashes), migrationIndex(m.migrations, migrationID)
+	if last >= 0 && position >= 0 {
+		return position <= last
+	}
+	return migrationID != baseline.id && migrationID <= baseline.squashes
+}
+
+// unmarkSquashed removes the migrations squashed by a baseline that is rolled back, as dropping its schema
+// also undoes them
+func (m *migrationManager) unmarkSquashed(tx *gorm.DB, baseline migration) error {
+	if baseline.squashes == "" {
+		return nil
+	}
+	var appliedIDs []string
+	err := tx.Table(m.options.TableName).Pluck(m.options.IDColumnName, &appliedIDs).Error
+	if err != nil {
+		return err
+	}
+	squashed := make([]string, 0)
+	for _, appliedID := range appliedIDs {
+		if m.squashedBy(baseline, appliedID) {
+			squashed = append(squashed, appliedID)
+		}
+	}
+	return removeRecords(tx, m.options, squashed)
+}
@@ -0,0 +1,122 @@
+package migrationhandler_test
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	migrationhandler "github.com/jvfrodrigues/gorm-migration-handler"
+	"gorm.io/gorm"
+)
+
+func TestSquash(t *testing.T) {
+	dir := tempDir(t)
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+	configFor := func(name string) migrationhandler.DBConfig {
+		return migrationhandler.DBConfig{
+			Dialector:            sqlite.Open("file:squash_" + name + "?mode=memory&cache=shared"),
+			MigrationsFolderPath: "./" + dir,
+		}
+	}
+	// keeps the shared in memory databases alive between calls
+	for _, name := range []string{"clean", "fresh", "behind"} {
+		db, err := gorm.Open(configFor(name).Dialector, &gorm.Config{})
+		if err != nil {
+			t.Fatalf("test error: %v", err)
+		}
+		sqlDB, _ := db.DB()
+		defer sqlDB.Close()
+	}
+	writeMigration(t, dir, "1_users_up.sql", "CREATE TABLE users (id integer primary key, name text);")
+	writeMigration(t, dir, "1_users_down.sql", "DROP TABLE users;")
+	_, err := migrationhandler.RunMigrations(configFor("behind"))
+	if err != nil {
+		t.Fatalf("test error: %v", err)
+	}
+	writeMigration(t, dir, "2_orders_up.sql", "CREATE TABLE orders (id integer primary key, user_id integer);")
+	writeMigration(t, dir, "2_orders_down.sql", "DROP TABLE orders;")
+
+	err = migrationhandler.Squash(configFor("behind"), "baseline")
+	expectedError := errors.New("database has applied migrations, squash needs a clean database")
+	if err == nil || err.Error() != expectedError.Error() {
+		t.Errorf("expected: %+v, got: %+v", expectedError, err)
+	}
+	err = migrationhandler.Squash(configFor("clean"), "baseline")
+	if err != nil {
+		t.Fatalf("expected: %+v, got: %+v", nil, err)
+	}
+	migrations, err := migrationhandler.LoadMigrations(dir)
+	if err != nil {
+		t.Fatalf("test error: %v", err)
+	}
+	if len(migrations) != 3 || migrations[2].Name != "baseline" {
+		t.Fatalf("expected the squashed migrations and the baseline, got: %+v", migrations)
+	}
+	baseline := migrations[2]
+	if !strings.Contains(baseline.UpSQL, "-- squashes: 2\n") || !strings.Contains(baseline.UpSQL, "CREATE TABLE orders") {
+		t.Errorf("expected baseline up file with the squashed schema, got: %+v", baseline.UpSQL)
+	}
+	expectedDown := "DROP TABLE IF EXISTS users;\nDROP TABLE IF EXISTS orders;\n"
+	if !strings.Contains(baseline.DownSQL, expectedDown) {
+		t.Errorf("expected baseline down file: %+v, got: %+v", expectedDown, baseline.DownSQL)
+	}
+
+	testCases := []struct {
+		desc            string
+		name            string
+		expectedApplied []string
+	}{
+		{
+			desc:            "fresh database applies only the baseline",
+			name:            "fresh",
+			expectedApplied: []string{baseline.ID},
+		},
+		{
+			desc:            "database with squashed migrations applies the pending ones and marks the baseline",
+			name:            "behind",
+			expectedApplied: []string{"2", baseline.ID},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			applied, err := migrationhandler.RunMigrations(configFor(tc.name))
+			if err != nil {
+				t.Fatalf("expected: %+v, got: %+v", nil, err)
+			}
+			ids := make([]string, 0, len(applied))
+			for _, migration := range applied {
+				ids = append(ids, migration.ID)
+			}
+			if strings.Join(ids, ",") != strings.Join(tc.expectedApplied, ",") {
+				t.Errorf("expected applied: %+v, got: %+v", tc.expectedApplied, ids)
+			}
+			status, err := migrationhandler.MigrationStatus(configFor(tc.name))
+			if err != nil {
+				t.Fatalf("test error: %v", err)
+			}
+			for _, info := range status {
+				if !info.Applied {
+					t.Errorf("expected migration %s to be applied", info.ID)
+				}
+			}
+		})
+	}
+
+	_, err = migrationhandler.RollbackMigration(configFor("fresh"))
+	if err != nil {
+		t.Fatalf("expected: %+v, got: %+v", nil, err)
+	}
+	status, err := migrationhandler.MigrationStatus(configFor("fresh"))
+	if err != nil {
+		t.Fatalf("test error: %v", err)
+	}
+	for _, info := range status {
+		if info.Applied {
+			t.Errorf("expected rolling back the baseline to roll back migration %s too", info.ID)
+		}
+	}
+}
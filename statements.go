@@ -0,0 +1,165 @@
+package migrationhandler
+
+import (
+	"strings"
+	"unicode"
+
+	"gorm.io/gorm"
+)
+
+// execStatements runs every statement of the given SQL one after the other, as most drivers only
+// execute the first statement when given many at once. SQL with only comments or whitespace runs nothing,
+// as some drivers error on empty statements
+func execStatements(db *gorm.DB, sql string) error {
+	for _, statement := range splitStatements(sql) {
+		err := db.Exec(statement).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// splitStatements splits the content of a migration file into its statements on every semicolon
+// that is not inside of a quoted string, a comment or a BEGIN...END block of a CREATE statement,
+// like the body of a trigger. Statements containing only comments or whitespace are dropped.
+// Files with a "-- delimiter <delimiter>" header, like "-- delimiter $$", are only split on that delimiter,
+// so the semicolons of stored procedures are left alone
+func splitStatements(sql string) []string {
+	delimiter, ok := directiveValue(sql, delimiterDirective)
+	if !ok || delimiter == "" {
+		delimiter = ";"
+	}
+	statements := make([]string, 0)
+	var current strings.Builder
+	var word strings.Builder
+	hasContent := false
+	firstWord := ""
+	blockDepth := 0
+	runes := []rune(sql)
+	flush := func() {
+		if hasContent {
+			statements = append(statements, strings.TrimSpace(current.String()))
+		}
+		current.Reset()
+		hasContent = false
+		firstWord = ""
+	}
+	endWord := func() {
+		if word.Len() == 0 {
+			return
+		}
+		upperWord := strings.ToUpper(word.String())
+		if firstWord == "" {
+			firstWord = upperWord
+		}
+		switch upperWord {
+		case "BEGIN":
+			if blockDepth > 0 || firstWord == "CREATE" {
+				blockDepth++
+			}
+		case "CASE":
+			if blockDepth > 0 {
+				blockDepth++
+			}
+		case "END":
+			if blockDepth > 0 {
+				blockDepth--
+			}
+		}
+		word.Reset()
+	}
+	for i := 0; i < len(runes); i++ {
+		char := runes[i]
+		switch {
+		case char == '-' && i+1 < len(runes) && runes[i+1] == '-':
+			endWord()
+			end := indexFrom(runes, i, "\n")
+			current.WriteString(string(runes[i:end]))
+			i = end - 1
+			continue
+		case char == '/' && i+1 < len(runes) && runes[i+1] == '*':
+			endWord()
+			end := indexFrom(runes, i+2, "*/")
+			if end < len(runes) {
+				end += 2
+			}
+			current.WriteString(string(runes[i:end]))
+			i = end - 1
+			continue
+		case char == '\'' || char == '"' || char == '`':
+			endWord()
+			end := indexFrom(runes, i+1, string(char))
+			if end < len(runes) {
+				end++
+			}
+			current.WriteString(string(runes[i:end]))
+			hasContent = true
+			i = end - 1
+			continue
+		case delimiter != ";" && hasPrefixAt(runes, i, delimiter):
+			endWord()
+			flush()
+			i += len([]rune(delimiter)) - 1
+			continue
+		case char == '$' && word.Len() == 0:
+			if tag, ok := dollarQuoteTag(runes, i); ok {
+				end := indexFrom(runes, i+len(tag), tag)
+				if end < len(runes) {
+					end += len(tag)
+				}
+				current.WriteString(string(runes[i:end]))
+				hasContent = true
+				i = end - 1
+				continue
+			}
+		}
+		if unicode.IsLetter(char) || unicode.IsDigit(char) || char == '_' {
+			word.WriteRune(char)
+		} else {
+			endWord()
+		}
+		if char == ';' && blockDepth == 0 && delimiter == ";" {
+			flush()
+			continue
+		}
+		current.WriteRune(char)
+		if !unicode.IsSpace(char) {
+			hasContent = true
+		}
+	}
+	endWord()
+	flush()
+	return statements
+}
+
+// indexFrom returns the index of the first occurrence of value in runes at or after start,
+// or the length of runes when it is not found
+func indexFrom(runes []rune, start int, value string) int {
+	search := []rune(value)
+	for i := start; i+len(search) <= len(runes); i++ {
+		if string(runes[i:i+len(search)]) == value {
+			return i
+		}
+	}
+	return len(runes)
+}
+
+// hasPrefixAt checks if runes has value at index
+func hasPrefixAt(runes []rune, index int, value string) bool {
+	search := []rune(value)
+	return index+len(search) <= len(runes) && string(runes[index:index+len(search)]) == value
+}
+
+// dollarQuoteTag returns the tag of a postgres dollar quoted string starting at index, like $$ or $body$
+func dollarQuoteTag(runes []rune, index int) (string, bool) {
+	for i := index + 1; i < len(runes); i++ {
+		if runes[i] == '$' {
+			return string(runes[index : i+1]), true
+		}
+		if !unicode.IsLetter(runes[i]) && runes[i] != '_' {
+			return "", false
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,254 @@
+package migrationhandler_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	migrationhandler "github.com/jvfrodrigues/gorm-migration-handler"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestRunMigrationsMultipleStatements(t *testing.T) {
+	dialector := sqlite.Open("file:statements_test?mode=memory&cache=shared")
+	db, err := gorm.Open(dialector, &gorm.Config{
+		SkipDefaultTransaction: true,
+		Logger:                 logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("test error: %v", err)
+	}
+	dir := tempDir(t)
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+	dbConfig := migrationhandler.DBConfig{
+		Dialector:            dialector,
+		MigrationsFolderPath: "./" + dir,
+	}
+	writeMigration(t, dir, "1_test_up.sql", `-- Write your SQL command here
+CREATE TABLE statements_first (id integer, name text);
+CREATE TABLE statements_second (id integer, name text);
+/* names with semicolons; should not be split */
+INSERT INTO statements_first (id, name) VALUES (1, 'first; value');
+CREATE TRIGGER statements_copy AFTER INSERT ON statements_first
+BEGIN
+	INSERT INTO statements_second (id, name) VALUES (new.id, new.name);
+	UPDATE statements_second SET name = CASE WHEN new.id > 1 THEN 'big;' ELSE name END WHERE id = new.id;
+END;
+INSERT INTO statements_first (id, name) VALUES (2, "second");
+`)
+	writeMigration(t, dir, "1_test_down.sql", `DROP TRIGGER statements_copy;
+DROP TABLE statements_second;
+DROP TABLE statements_first;`)
+	_, err = migrationhandler.RunMigrations(dbConfig)
+	if err != nil {
+		t.Fatalf("expected: %+v, got: %+v", nil, err)
+	}
+	tests := []struct {
+		name          string
+		table         string
+		expectedCount int64
+	}{
+		{
+			name:          "Test if every statement ran on the first table",
+			table:         "statements_first",
+			expectedCount: 2,
+		},
+		{
+			name:          "Test if the trigger body ran as a single statement",
+			table:         "statements_second",
+			expectedCount: 1,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var count int64
+			db.Table(tc.table).Count(&count)
+			if count != tc.expectedCount {
+				t.Errorf("expected: %+v, got: %+v", tc.expectedCount, count)
+			}
+		})
+	}
+	var name string
+	db.Table("statements_second").Select("name").Where("id = ?", 2).Scan(&name)
+	if name != "big;" {
+		t.Errorf("expected: %+v, got: %+v", "big;", name)
+	}
+	_, err = migrationhandler.RollbackMigration(dbConfig)
+	if err != nil {
+		t.Fatalf("expected: %+v, got: %+v", nil, err)
+	}
+	if db.Migrator().HasTable("statements_first") || db.Migrator().HasTable("statements_second") {
+		t.Errorf("expected every table to be dropped on rollback")
+	}
+}
+
+func TestRunMigrationsDelimiter(t *testing.T) {
+	dialector := sqlite.Open("file:delimiter_test?mode=memory&cache=shared")
+	db, err := gorm.Open(dialector, &gorm.Config{
+		SkipDefaultTransaction: true,
+		Logger:                 logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("test error: %v", err)
+	}
+	dir := tempDir(t)
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+	dbConfig := migrationhandler.DBConfig{
+		Dialector:            dialector,
+		MigrationsFolderPath: "./" + dir,
+	}
+	writeMigration(t, dir, "1_test_up.sql", `-- delimiter $$
+CREATE TABLE delimiter_first (id integer, name text)$$
+CREATE TABLE delimiter_second (id integer, name text)$$
+CREATE TRIGGER delimiter_copy AFTER INSERT ON delimiter_first
+BEGIN
+	INSERT INTO delimiter_second (id, name) VALUES (new.id, new.name);
+	INSERT INTO delimiter_second (id, name) VALUES (new.id + 1, 'cost: $$ 5');
+END$$
+INSERT INTO delimiter_first (id, name) VALUES (1, 'first')$$
+`)
+	writeMigration(t, dir, "1_test_down.sql", `-- delimiter //
+DROP TABLE delimiter_first//
+DROP TABLE delimiter_second//
+`)
+	_, err = migrationhandler.RunMigrations(dbConfig)
+	if err != nil {
+		t.Fatalf("expected: %+v, got: %+v", nil, err)
+	}
+	var names []string
+	db.Table("delimiter_second").Order("id").Pluck("name", &names)
+	expected := []string{"first", "cost: $$ 5"}
+	if fmt.Sprint(names) != fmt.Sprint(expected) {
+		t.Errorf("expected: %+v, got: %+v", expected, names)
+	}
+	_, err = migrationhandler.RollbackMigration(dbConfig)
+	if err != nil {
+		t.Fatalf("expected: %+v, got: %+v", nil, err)
+	}
+	if db.Migrator().HasTable("delimiter_first") || db.Migrator().HasTable("delimiter_second") {
+		t.Errorf("expected every table to be dropped on rollback")
+	}
+}
+
+func TestRunMigrationsEmpty(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{
+			name:    "Test if an empty file is recorded without running anything",
+			content: "",
+		},
+		{
+			name:    "Test if a file with only the template comment is recorded without running anything",
+			content: "-- Write your SQL command here\n",
+		},
+		{
+			name:    "Test if a file with only whitespace and comments is recorded without running anything",
+			content: "\n  \t\n/* nothing yet */\n-- still nothing\n;\n",
+		},
+	}
+	for i, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			db, err := gorm.Open(sqlite.Open(fmt.Sprintf("file:empty_statements_test_%d?mode=memory&cache=shared", i)), &gorm.Config{
+				Logger: logger.Default.LogMode(logger.Silent),
+			})
+			if err != nil {
+				t.Fatalf("test error: %v", err)
+			}
+			// acts like the drivers that error on empty statements
+			err = db.Callback().Raw().Before("gorm:raw").Register("test:reject_empty", func(tx *gorm.DB) {
+				if strings.TrimSpace(tx.Statement.SQL.String()) == "" {
+					_ = tx.AddError(errors.New("empty statement"))
+				}
+			})
+			if err != nil {
+				t.Fatalf("test error: %v", err)
+			}
+			dir := tempDir(t)
+			defer func() {
+				_ = os.RemoveAll(dir)
+			}()
+			dbConfig := migrationhandler.DBConfig{
+				DB:                   db,
+				MigrationsFolderPath: "./" + dir,
+			}
+			writeMigration(t, dir, "1_empty_up.sql", tc.content)
+			writeMigration(t, dir, "1_empty_down.sql", tc.content)
+			applied, err := migrationhandler.RunMigrations(dbConfig)
+			if err != nil {
+				t.Fatalf("expected: %+v, got: %+v", nil, err)
+			}
+			if len(applied) != 1 || applied[0].ID != "1" {
+				t.Errorf("expected the empty migration to be applied, got: %+v", applied)
+			}
+			_, err = migrationhandler.RollbackMigration(dbConfig)
+			if err != nil {
+				t.Errorf("expected: %+v, got: %+v", nil, err)
+			}
+		})
+	}
+}
+
+func TestRunMigrationsSQLTransform(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:sql_transform_test?mode=memory&cache=shared"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("test error: %v", err)
+	}
+	dir := tempDir(t)
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+	dbConfig := migrationhandler.DBConfig{
+		DB:                   db,
+		MigrationsFolderPath: "./" + dir,
+		SQLTransform: func(sql string) string {
+			return strings.ReplaceAll(sql, "{{tenant}}", "acme")
+		},
+	}
+	writeMigration(t, dir, "1_users_up.sql", "CREATE TABLE {{tenant}}_users (id integer);")
+	writeMigration(t, dir, "1_users_down.sql", "DROP TABLE {{tenant}}_users;")
+	tests := []struct {
+		name          string
+		run           func() error
+		expectedTable bool
+	}{
+		{
+			name: "Test if the up SQL is transformed before running",
+			run: func() error {
+				_, err := migrationhandler.RunMigrations(dbConfig)
+				return err
+			},
+			expectedTable: true,
+		},
+		{
+			name: "Test if the down SQL is transformed before running",
+			run: func() error {
+				_, err := migrationhandler.RollbackMigration(dbConfig)
+				return err
+			},
+			expectedTable: false,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.run()
+			if err != nil {
+				t.Fatalf("expected: %+v, got: %+v", nil, err)
+			}
+			if db.Migrator().HasTable("acme_users") != tc.expectedTable {
+				t.Errorf("expected table acme_users to exist: %v", tc.expectedTable)
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package migrationhandler
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+)
+
+// MigrationInfo describes a migration found on the migrations folder or on the database and if it was already applied
+type MigrationInfo struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Applied   bool      `json:"applied"`
+	AppliedAt time.Time `json:"applied_at"`
+}
+
+// MigrationStatus gets DB info and gets all migrations from given folder to list which of them are applied or pending
+func MigrationStatus(dbConfig DBConfig) ([]MigrationInfo, error) {
+	db, err := newDatabase(dbConfig)
+	if err != nil {
+		return nil, fmt.Errorf("%w, can not get migrations status: %w", ErrConnectionFailed, err)
+	}
+	migrations, err := getMigrations(dbConfig)
+	if err != nil {
+		return nil, err
+	}
+	applied, err := getAppliedMigrations(db, managerOptions(dbConfig))
+	if err != nil {
+		return nil, err
+	}
+	status := make([]MigrationInfo, 0, len(migrations))
+	for _, migration := range migrations {
+		appliedAt, ok := applied[migration.id]
+		status = append(status, MigrationInfo{
+			ID:        migration.id,
+			Name:      migration.name,
+			Applied:   ok,
+			AppliedAt: appliedAt,
+		})
+		delete(applied, migration.id)
+	}
+	// applied migrations without a file go before the first migration with a greater ID, so without a manifest
+	// the whole list stays sorted by ID
+	missing := make([]string, 0, len(applied))
+	for id := range applied {
+		missing = append(missing, id)
+	}
+	sort.Strings(missing)
+	for _, id := range missing {
+		position := len(status)
+		for i, info := range status {
+			if info.ID > id {
+				position = i
+				break
+			}
+		}
+		status = slices.Insert(status, position, MigrationInfo{ID: id, Applied: true, AppliedAt: applied[id]})
+	}
+	return status, nil
+}
+
+// getAppliedMigrations returns the IDs on the migrations table with the time they were applied,
+// which is zero when it was not recorded
+func getAppliedMigrations(db *database, options *gormigrate.Options) (map[string]time.Time, error) {
+	applied := make(map[string]time.Time)
+	if !db.Db.Migrator().HasTable(options.TableName) {
+		return applied, nil
+	}
+	var ids []string
+	err := db.Db.Table(options.TableName).Pluck(options.IDColumnName, &ids).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		applied[id] = time.Time{}
+	}
+	if !db.Db.Migrator().HasTable(recordsTableName(options)) {
+		return applied, nil
+	}
+	var records []migrationRecord
+	err = db.Db.Table(recordsTableName(options)).Find(&records).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, record := range records {
+		if _, ok := applied[record.ID]; ok {
+			applied[record.ID] = record.AppliedAt
+		}
+	}
+	return applied, nil
+}
@@ -0,0 +1,77 @@
+package migrationhandler_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	migrationhandler "github.com/jvfrodrigues/gorm-migration-handler"
+)
+
+func writeMigration(t *testing.T, dir string, fileName string, content string) {
+	err := os.WriteFile(dir+"/"+fileName, []byte(content), 0o644)
+	if err != nil {
+		t.Fatalf("test error: %v", err)
+	}
+}
+
+func TestMigrationStatus(t *testing.T) {
+	dir := tempDir(t)
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+	dbConfig := migrationhandler.DBConfig{
+		Dialector:            sqlite.Open("file:status_test?mode=memory&cache=shared"),
+		MigrationsFolderPath: "./" + dir,
+	}
+	writeMigration(t, dir, "1_first_up.sql", "CREATE TABLE status_first (id integer);")
+	writeMigration(t, dir, "1_first_down.sql", "DROP TABLE status_first;")
+	_, err := migrationhandler.RunMigrations(dbConfig)
+	if err != nil {
+		t.Fatalf("test error: %v", err)
+	}
+	writeMigration(t, dir, "2_second_up.sql", "CREATE TABLE status_second (id integer);")
+	writeMigration(t, dir, "2_second_down.sql", "DROP TABLE status_second;")
+	status, err := migrationhandler.MigrationStatus(dbConfig)
+	if err != nil {
+		t.Fatalf("expected: %+v, got: %+v", nil, err)
+	}
+	if len(status) != 2 {
+		t.Fatalf("expected: %+v migrations, got: %+v", 2, len(status))
+	}
+	tests := []struct {
+		name            string
+		info            migrationhandler.MigrationInfo
+		expectedID      string
+		expectedName    string
+		expectedApplied bool
+	}{
+		{
+			name:            "Test if applied migration is listed as applied",
+			info:            status[0],
+			expectedID:      "1",
+			expectedName:    "first",
+			expectedApplied: true,
+		},
+		{
+			name:            "Test if pending migration is listed as not applied",
+			info:            status[1],
+			expectedID:      "2",
+			expectedName:    "second",
+			expectedApplied: false,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.info.ID != tc.expectedID || tc.info.Name != tc.expectedName {
+				t.Errorf("expected: %v_%v, got: %v_%v", tc.expectedID, tc.expectedName, tc.info.ID, tc.info.Name)
+			}
+			if tc.info.Applied != tc.expectedApplied {
+				t.Errorf("expected applied: %+v, got: %+v", tc.expectedApplied, tc.info.Applied)
+			}
+			if tc.info.Applied == tc.info.AppliedAt.IsZero() {
+				t.Errorf("expected applied at to be set only when applied, got: %+v", tc.info.AppliedAt)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package migrationhandler_test
+
+import (
+	"bytes"
+	"database/sql"
+	"log"
+	"os"
+	"strings"
+	"testing"
+
+	migrationhandler "github.com/jvfrodrigues/gorm-migration-handler"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestRunMigrationsTableSchema(t *testing.T) {
+	sqlDB, err := sql.Open("empty", "")
+	if err != nil {
+		t.Fatalf("test error: %v", err)
+	}
+	defer sqlDB.Close()
+	tests := []struct {
+		name            string
+		tableName       string
+		tableSchema     string
+		expectedLogs    []string
+		notExpectedLogs []string
+	}{
+		{
+			name:         "Test if the migrations tables are created in the schema",
+			tableSchema:  "bookkeeping",
+			expectedLogs: []string{`CREATE SCHEMA IF NOT EXISTS "bookkeeping"`, `CREATE TABLE "bookkeeping"."migrations"`, `CREATE TABLE "bookkeeping"."migrations_records"`},
+		},
+		{
+			name:         "Test if the schema is combined with the table name",
+			tableName:    "schema_migrations",
+			tableSchema:  "bookkeeping",
+			expectedLogs: []string{`CREATE SCHEMA IF NOT EXISTS "bookkeeping"`, `CREATE TABLE "bookkeeping"."schema_migrations"`},
+		},
+		{
+			name:            "Test if no schema is created by default",
+			expectedLogs:    []string{`CREATE TABLE "migrations"`},
+			notExpectedLogs: []string{"CREATE SCHEMA"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := tempDir(t)
+			defer func() {
+				_ = os.RemoveAll(dir)
+			}()
+			var output bytes.Buffer
+			db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{
+				Logger: logger.New(log.New(&output, "", 0), logger.Config{Colorful: false}),
+			})
+			if err != nil {
+				t.Fatalf("test error: %v", err)
+			}
+			writeMigration(t, dir, "1_users_up.sql", "CREATE TABLE users (id integer);")
+			writeMigration(t, dir, "1_users_down.sql", "DROP TABLE users;")
+			_, err = migrationhandler.RunMigrations(migrationhandler.DBConfig{
+				DB:                   db,
+				MigrationsFolderPath: "./" + dir,
+				TableName:            tc.tableName,
+				TableSchema:          tc.tableSchema,
+				LogLevel:             logger.Info,
+				SkipLock:             true,
+			})
+			if err != nil {
+				t.Fatalf("expected: %+v, got: %+v", nil, err)
+			}
+			for _, expected := range tc.expectedLogs {
+				if !strings.Contains(output.String(), expected) {
+					t.Errorf("expected %s to be run, got: %+v", expected, output.String())
+				}
+			}
+			for _, notExpected := range tc.notExpectedLogs {
+				if strings.Contains(output.String(), notExpected) {
+					t.Errorf("expected %s to not be run, got: %+v", notExpected, output.String())
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package migrationhandler
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// TransactionMode sets how migrations are wrapped in transactions when they are run or rolled back.
+// Only one transaction is ever open at a time, as not every driver supports nested transactions
+type TransactionMode int
+
+const (
+	// TransactionPerMigration runs every migration in its own transaction, so when one fails
+	// the migrations before it stay applied
+	TransactionPerMigration TransactionMode = iota
+	// TransactionPerRun runs every migration of a run in a single transaction opened by gormigrate,
+	// so when one fails all of them are rolled back. It is the same as gormigrate's UseTransaction option
+	TransactionPerRun
+)
+
+// transaction runs fn inside of a transaction for a single migration, unless gormigrate already
+// wraps the whole run in one or the dialect has no transactional DDL. Files with a "-- no-transaction"
+// header run fn directly on db, for statements like CREATE INDEX CONCURRENTLY, which can not happen
+// when the whole run is a transaction
+func (m *migrationManager) transaction(db *gorm.DB, noTransaction bool, fn func(tx *gorm.DB) error) error {
+	if noTransaction && m.options.UseTransaction {
+		return errors.New("migrations with a no-transaction directive can not run with TransactionPerRun")
+	}
+	if noTransaction || m.options.UseTransaction || !m.transactionalDDL {
+		return fn(db)
+	}
+	tx := db.Begin()
+	defer tx.Rollback()
+	err := fn(tx)
+	if err != nil {
+		return err
+	}
+	return tx.Commit().Error
+}
+
+// hasTransactionalDDL tells if DDL statements of the dialect can be rolled back, MySQL commits them implicitly
+// so wrapping them in a transaction only hides that a failed migration can leave partial changes
+func hasTransactionalDDL(dialect string) bool {
+	return dialect != "mysql"
+}
@@ -0,0 +1,70 @@
+package migrationhandler_test
+
+import (
+	"database/sql"
+	"fmt"
+	"testing"
+	"testing/fstest"
+
+	migrationhandler "github.com/jvfrodrigues/gorm-migration-handler"
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestRunMigrationsTransactionalDDL(t *testing.T) {
+	sqlDB, err := sql.Open("empty", "")
+	if err != nil {
+		t.Fatalf("test error: %v", err)
+	}
+	defer sqlDB.Close()
+	tests := []struct {
+		name                string
+		dialector           gorm.Dialector
+		expectedTransaction bool
+		expectedMessage     string
+	}{
+		{
+			name:                "Test if mysql migrations run without a transaction",
+			dialector:           mysql.New(mysql.Config{Conn: sqlDB, SkipInitializeWithVersion: true}),
+			expectedTransaction: false,
+			expectedMessage:     "INFO mysql commits DDL statements implicitly, migrations run without a transaction so a failed one can leave partial changes",
+		},
+		{
+			name:                "Test if postgres migrations run in a transaction",
+			dialector:           postgres.New(postgres.Config{Conn: sqlDB}),
+			expectedTransaction: true,
+			expectedMessage:     "INFO Migrations successful, 1 applied",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			db, err := gorm.Open(tc.dialector, &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+			if err != nil {
+				t.Fatalf("test error: %v", err)
+			}
+			logger := &testLogger{}
+			begins := emptyBegins.Load()
+			_, err = migrationhandler.RunMigrations(migrationhandler.DBConfig{
+				DB: db,
+				MigrationsFS: fstest.MapFS{
+					"migrations/1_users_up.sql":   {Data: []byte("CREATE TABLE users (id integer);")},
+					"migrations/1_users_down.sql": {Data: []byte("DROP TABLE users;")},
+				},
+				MigrationsFolderPath: "migrations",
+				Logger:               logger,
+				SkipLock:             true,
+			})
+			if err != nil {
+				t.Fatalf("expected: %+v, got: %+v", nil, err)
+			}
+			if transaction := emptyBegins.Load() > begins; transaction != tc.expectedTransaction {
+				t.Errorf("expected transaction: %+v, got: %+v", tc.expectedTransaction, transaction)
+			}
+			if len(logger.messages) == 0 || logger.messages[0] != tc.expectedMessage {
+				t.Errorf("expected first message: %+v, got: %+v", tc.expectedMessage, fmt.Sprint(logger.messages))
+			}
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package migrationhandler
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var dropTableFilter = regexp.MustCompile("(?i)^DROP TABLE\\s+(?:IF EXISTS\\s+)?([`\"\\[]?[\\w.]+[`\"\\]]?)")
+
+var dropColumnFilter = regexp.MustCompile("(?i)^ALTER TABLE\\s+([`\"\\[]?[\\w.]+[`\"\\]]?)\\s+DROP\\s+(?:COLUMN\\s+)?([`\"\\[]?\\w+[`\"\\]]?)")
+
+// Validate checks the migration files of the folder at path without connecting to a database, returning an
+// error listing every problem found instead of stopping at the first one: up and down files not starting with
+// a migration ID, duplicated files and IDs, files missing their pair, IDs that do not sort in numeric order and
+// tables or columns created by more than one migration. Up files with only comments, as CreateMigration writes
+// them, are valid
+func Validate(path string) error {
+	return ValidateConfig(DBConfig{MigrationsFolderPath: path})
+}
+
+// ValidateConfig is Validate for the migrations of dbConfig, read the way they are run: with its file suffixes
+// or patterns, folders, Go migrations and ordering manifest. IDs are not checked to sort in numeric order when a
+// manifest sets the order instead
+func ValidateConfig(dbConfig DBConfig) error {
+	migrations, problems, err := readMigrations(dbConfig)
+	if err != nil {
+		return err
+	}
+	found := make([]error, 0)
+	for _, fileName := range problems.misnamedFiles {
+		found = append(found, fmt.Errorf("file %s does not start with a migration ID", fileName))
+	}
+	found = append(found, problems.duplicatedFiles...)
+	for _, check := range []func(map[string]migration) error{validateUpFiles, validatePairs} {
+		if err := check(migrations); err != nil {
+			found = append(found, err)
+		}
+	}
+	if err := addGoMigrations(dbConfig, migrations); err != nil {
+		found = append(found, err)
+	}
+	sorted := sortMigrations(migrations)
+	checks := []func([]migration) error{validateIDs}
+	ordered, err := hasOrderFile(dbConfig)
+	if err != nil {
+		return err
+	}
+	if !ordered {
+		checks = append(checks, validateOrder)
+	}
+	for _, check := range checks {
+		if err := check(sorted); err != nil {
+			found = append(found, err)
+		}
+	}
+	if ordered {
+		inOrder, err := orderMigrations(dbConfig, sorted)
+		if err != nil {
+			found = append(found, err)
+		} else {
+			sorted = inOrder
+		}
+	}
+	for i := range sorted {
+		migration := &sorted[i]
+		if err := migration.loadUp(); err != nil {
+			found = append(found, err)
+		}
+	}
+	if err := validateCreations(sorted); err != nil {
+		found = append(found, err)
+	}
+	return errors.Join(found...)
+}
+
+// validateCreations finds the tables created and the columns added by more than one up file, which fail when the
+// later one runs. Statements are matched with regular expressions, so only plain CREATE TABLE, ALTER TABLE ADD
+// and their DROP counterparts are seen, and a baseline written by Squash starts over from an empty schema
+func validateCreations(sorted []migration) error {
+	createdBy := make(map[string]string)
+	offenders := make([]string, 0)
+	create := func(name string, fileName string) {
+		if previous, ok := createdBy[name]; ok {
+			offenders = append(offenders, fmt.Sprintf("%s (%s, %s)", name, previous, fileName))
+		}
+		createdBy[name] = fileName
+	}
+	for _, migration := range sorted {
+		if migration.squashes != "" {
+			createdBy = make(map[string]string)
+		}
+		for _, statement := range splitStatements(migration.migrationSQL) {
+			statement = withoutLeadingComments(statement)
+			if match := createTableFilter.FindStringSubmatch(statement); match != nil {
+				create(objectName(match[1]), migration.fileName())
+			} else if match := addColumnFilter.FindStringSubmatch(statement); match != nil && !addKeywords[strings.ToUpper(match[2])] {
+				create(objectName(match[1])+"."+objectName(match[2]), migration.fileName())
+			} else if match := dropTableFilter.FindStringSubmatch(statement); match != nil {
+				table := objectName(match[1])
+				for name := range createdBy {
+					if name == table || strings.HasPrefix(name, table+".") {
+						delete(createdBy, name)
+					}
+				}
+			} else if match := dropColumnFilter.FindStringSubmatch(statement); match != nil {
+				delete(createdBy, objectName(match[1])+"."+objectName(match[2]))
+			}
+		}
+	}
+	if len(offenders) > 0 {
+		return fmt.Errorf("tables or columns created more than once: %s", strings.Join(offenders, ", "))
+	}
+	return nil
+}
+
+// withoutLeadingComments drops the comment lines before the first line of a statement
+func withoutLeadingComments(statement string) string {
+	lines := strings.Split(strings.TrimSpace(statement), "\n")
+	for len(lines) > 1 && strings.HasPrefix(strings.TrimSpace(lines[0]), "--") {
+		lines = lines[1:]
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// objectName is the name of a table or column as it is compared, without quotes and in lower case
+func objectName(name string) string {
+	return strings.ToLower(strings.Trim(name, "`\"[]"))
+}
+
+// validateOrder makes sure sorting the IDs as text, the order migrations run in, matches their numeric order,
+// which breaks when they have different lengths like 9 and 10
+func validateOrder(sorted []migration) error {
+	for i := 1; i < len(sorted); i++ {
+		previous, err := strconv.ParseFloat(sorted[i-1].id, 64)
+		if err != nil {
+			continue
+		}
+		current, err := strconv.ParseFloat(sorted[i].id, 64)
+		if err != nil {
+			continue
+		}
+		if previous > current {
+			return fmt.Errorf("migration %s runs before migration %s as IDs are sorted as text, give them the same length", sorted[i-1].id, sorted[i].id)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+package migrationhandler_test
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	migrationhandler "github.com/jvfrodrigues/gorm-migration-handler"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name          string
+		files         map[string]string
+		expectedError error
+	}{
+		{
+			name: "Test if a valid folder has no problems",
+			files: map[string]string{
+				"1_users_up.sql":   "CREATE TABLE users (id integer);",
+				"1_users_down.sql": "DROP TABLE users;",
+				"1_users_seed.sql": "INSERT INTO users (id) VALUES (1);",
+				"schema.sql":       "CREATE TABLE users (id integer);",
+			},
+			expectedError: nil,
+		},
+		{
+			name: "Test if an up file with only comments is valid",
+			files: map[string]string{
+				"1_users_up.sql":   "-- Write your migration SQL here\n",
+				"1_users_down.sql": "-- Write your rollback SQL here\n",
+			},
+			expectedError: nil,
+		},
+		{
+			name: "Test if every problem is listed",
+			files: map[string]string{
+				"users_up.sql":      "CREATE TABLE users (id integer);",
+				"1_users_up.sql":    "CREATE TABLE users (id integer);",
+				"2_orders_up.sql":   "-- nothing to do yet\n",
+				"2_orders_down.sql": "DROP TABLE orders;",
+				"3_items_down.sql":  "DROP TABLE items;",
+				"9_first_up.sql":    "SELECT 1;",
+				"9_first_down.sql":  "SELECT 1;",
+				"9_second_up.sql":   "SELECT 2;",
+				"9_second_down.sql": "SELECT 2;",
+				"10_last_up.sql":    "SELECT 10;",
+				"10_last_down.sql":  "SELECT 10;",
+			},
+			expectedError: errors.Join(
+				errors.New("file users_up.sql does not start with a migration ID"),
+				errors.New("down files without an up file: 3_items_down.sql"),
+				errors.New("incomplete migrations: 1_users is missing its down file"),
+				errors.New("duplicated migration IDs: 9 (9_first_up.sql, 9_second_up.sql)"),
+				errors.New("migration 10 runs before migration 2 as IDs are sorted as text, give them the same length"),
+			),
+		},
+		{
+			name: "Test if tables and columns created by more than one migration are listed",
+			files: map[string]string{
+				"1_users_up.sql":         "CREATE TABLE users (id integer);",
+				"1_users_down.sql":       "DROP TABLE users;",
+				"2_users_copy_up.sql":    "-- copied by mistake\nCREATE TABLE IF NOT EXISTS `Users` (id integer);",
+				"2_users_copy_down.sql":  "SELECT 1;",
+				"3_email_up.sql":         "ALTER TABLE users ADD COLUMN email text; ALTER TABLE users ADD CONSTRAINT unique_id UNIQUE (id);",
+				"3_email_down.sql":       "ALTER TABLE users DROP COLUMN email;",
+				"4_email_again_up.sql":   "ALTER TABLE users ADD email text;",
+				"4_email_again_down.sql": "SELECT 1;",
+			},
+			expectedError: errors.New("tables or columns created more than once: users (1_users_up.sql, 2_users_copy_up.sql), users.email (3_email_up.sql, 4_email_again_up.sql)"),
+		},
+		{
+			name: "Test if tables and columns can be created again after being dropped",
+			files: map[string]string{
+				"1_users_up.sql":      "CREATE TABLE users (id integer); ALTER TABLE users ADD COLUMN email text;",
+				"1_users_down.sql":    "DROP TABLE users;",
+				"2_email_up.sql":      "ALTER TABLE users DROP COLUMN email; ALTER TABLE users ADD COLUMN email varchar(255);",
+				"2_email_down.sql":    "SELECT 1;",
+				"3_recreate_up.sql":   "DROP TABLE IF EXISTS users; CREATE TABLE users (id bigint);",
+				"3_recreate_down.sql": "SELECT 1;",
+			},
+			expectedError: nil,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := tempDir(t)
+			defer func() {
+				_ = os.RemoveAll(dir)
+			}()
+			for fileName, content := range tc.files {
+				writeMigration(t, dir, fileName, content)
+			}
+			err := migrationhandler.Validate("./" + dir)
+			if err != nil || tc.expectedError != nil {
+				if err == nil || tc.expectedError == nil || err.Error() != tc.expectedError.Error() {
+					t.Errorf("expected: %+v, got: %+v", tc.expectedError, err)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateConfig(t *testing.T) {
+	tests := []struct {
+		name          string
+		upPattern     string
+		downPattern   string
+		files         map[string]string
+		expectedError error
+	}{
+		{
+			name:        "Test if files are found with the patterns of the config",
+			upPattern:   `^V(?P<id>\d+)__(?P<name>.+)_up\.sql$`,
+			downPattern: `^V(?P<id>\d+)__(?P<name>.+)_down\.sql$`,
+			files: map[string]string{
+				"V1__users_up.sql":   "CREATE TABLE users (id integer);",
+				"V1__users_down.sql": "DROP TABLE users;",
+			},
+			expectedError: nil,
+		},
+		{
+			name: "Test if IDs of different lengths are allowed with a manifest",
+			files: map[string]string{
+				"9_users_up.sql":     "CREATE TABLE users (id integer);",
+				"9_users_down.sql":   "DROP TABLE users;",
+				"10_orders_up.sql":   "CREATE TABLE orders (id integer);",
+				"10_orders_down.sql": "DROP TABLE orders;",
+				"migrations.order":   "9\n10\n",
+			},
+			expectedError: nil,
+		},
+		{
+			name: "Test if problems of the manifest are listed",
+			files: map[string]string{
+				"9_users_up.sql":     "CREATE TABLE users (id integer);",
+				"9_users_down.sql":   "DROP TABLE users;",
+				"10_orders_up.sql":   "CREATE TABLE orders (id integer);",
+				"10_orders_down.sql": "DROP TABLE orders;",
+				"migrations.order":   "9\n",
+			},
+			expectedError: errors.New("migration 10_orders is missing from migrations.order"),
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := tempDir(t)
+			defer func() {
+				_ = os.RemoveAll(dir)
+			}()
+			for fileName, content := range tc.files {
+				writeMigration(t, dir, fileName, content)
+			}
+			err := migrationhandler.ValidateConfig(migrationhandler.DBConfig{
+				MigrationsFolderPath: "./" + dir,
+				UpPattern:            tc.upPattern,
+				DownPattern:          tc.downPattern,
+			})
+			if err != nil || tc.expectedError != nil {
+				if err == nil || tc.expectedError == nil || err.Error() != tc.expectedError.Error() {
+					t.Errorf("expected: %+v, got: %+v", tc.expectedError, err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package migrationhandler
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// VerifyRollback gets DB info and gets all migrations from given folder to run each one up, down and up again,
+// checking that the down migration brings the schema back to the state it was before the up migration.
+// Nothing is recorded on the migrations table, so it only runs against an empty database, like an in memory SQLite,
+// and refuses any database that already has tables, the migrations table included
+func VerifyRollback(dbConfig DBConfig) error {
+	db, err := newDatabase(dbConfig)
+	if err != nil {
+		return fmt.Errorf("%w, can not verify migrations: %w", ErrConnectionFailed, err)
+	}
+	schema, err := getSchema(db.Db)
+	if err != nil {
+		return err
+	}
+	if len(schema) > 0 {
+		tables := make([]string, 0, len(schema))
+		for table := range schema {
+			tables = append(tables, table)
+		}
+		sort.Strings(tables)
+		return fmt.Errorf("can not verify migrations on a database that already has tables: %s", strings.Join(tables, ", "))
+	}
+	migrations, err := getMigrations(dbConfig)
+	if err != nil {
+		return err
+	}
+	if len(migrations) <= 0 {
+		return fmt.Errorf("%w to verify", ErrNoMigrations)
+	}
+	transform := getSQLTransform(dbConfig)
+	for _, migration := range migrations {
+		err = migration.load()
+		if err != nil {
+			return err
+		}
+		if migration.irreversible {
+			err = migration.runUp(db.Db, transform)
+			if err != nil {
+				return fmt.Errorf("migration %s_%s failed to migrate: %w", migration.id, migration.name, err)
+			}
+			continue
+		}
+		before, err := getSchema(db.Db)
+		if err != nil {
+			return err
+		}
+		err = migration.runUp(db.Db, transform)
+		if err != nil {
+			return fmt.Errorf("migration %s_%s failed to migrate: %w", migration.id, migration.name, err)
+		}
+		err = migration.runDown(db.Db, transform)
+		if err != nil {
+			return fmt.Errorf("migration %s_%s failed to rollback: %w", migration.id, migration.name, err)
+		}
+		after, err := getSchema(db.Db)
+		if err != nil {
+			return err
+		}
+		differences := compareSchemas(before, after)
+		if len(differences) > 0 {
+			return fmt.Errorf("migration %s_%s did not rollback cleanly: %s", migration.id, migration.name, strings.Join(differences, "; "))
+		}
+		err = migration.runUp(db.Db, transform)
+		if err != nil {
+			return fmt.Errorf("migration %s_%s failed to migrate after rollback: %w", migration.id, migration.name, err)
+		}
+	}
+	getLogger(dbConfig).Info("Rollback verification successful")
+	return nil
+}
+
+// getSchema describes the columns and indexes of every table on the database, keyed by table name
+func getSchema(db *gorm.DB) (map[string]string, error) {
+	// some drivers force debug logging while inspecting the schema, so every log is discarded here
+	db = db.Session(&gorm.Session{Logger: logger.Discard})
+	tables, err := db.Migrator().GetTables()
+	if err != nil {
+		return nil, err
+	}
+	schema := make(map[string]string)
+	for _, table := range tables {
+		if strings.HasPrefix(table, "sqlite_") {
+			continue
+		}
+		columnTypes, err := db.Migrator().ColumnTypes(table)
+		if err != nil {
+			return nil, err
+		}
+		definition := make([]string, 0)
+		for _, column := range columnTypes {
+			nullable, _ := column.Nullable()
+			definition = append(definition, fmt.Sprintf("column %s %s nullable=%v", column.Name(), column.DatabaseTypeName(), nullable))
+		}
+		// not every dialect can list indexes, in that case only columns are compared
+		indexes, err := db.Migrator().GetIndexes(table)
+		if err == nil {
+			for _, index := range indexes {
+				unique, _ := index.Unique()
+				definition = append(definition, fmt.Sprintf("index %s %v unique=%v", index.Name(), index.Columns(), unique))
+			}
+		}
+		sort.Strings(definition)
+		schema[table] = strings.Join(definition, ", ")
+	}
+	return schema, nil
+}
+
+func compareSchemas(before map[string]string, after map[string]string) []string {
+	differences := make([]string, 0)
+	for table, definition := range before {
+		afterDefinition, ok := after[table]
+		if !ok {
+			differences = append(differences, fmt.Sprintf("table %s is missing", table))
+		} else if afterDefinition != definition {
+			differences = append(differences, fmt.Sprintf("table %s changed from (%s) to (%s)", table, definition, afterDefinition))
+		}
+	}
+	for table := range after {
+		if _, ok := before[table]; !ok {
+			differences = append(differences, fmt.Sprintf("table %s was not dropped", table))
+		}
+	}
+	sort.Strings(differences)
+	return differences
+}
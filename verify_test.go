@@ -0,0 +1,99 @@
+package migrationhandler_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	migrationhandler "github.com/jvfrodrigues/gorm-migration-handler"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestVerifyRollback(t *testing.T) {
+	tests := []struct {
+		name          string
+		setup         string
+		files         map[string]string
+		expectedError error
+	}{
+		{
+			name: "Test if migrations that rollback cleanly are verified",
+			files: map[string]string{
+				"1_users_up.sql":       "CREATE TABLE users (id integer, name text);",
+				"1_users_down.sql":     "DROP TABLE users;",
+				"2_users_age_up.sql":   "ALTER TABLE users ADD COLUMN age integer;",
+				"2_users_age_down.sql": "ALTER TABLE users DROP COLUMN age;",
+			},
+			expectedError: nil,
+		},
+		{
+			name: "Test if it errors on migration that does not drop its table",
+			files: map[string]string{
+				"1_users_up.sql":    "CREATE TABLE users (id integer, name text);",
+				"1_users_down.sql":  "DROP TABLE users;",
+				"2_orders_up.sql":   "CREATE TABLE orders (id integer);",
+				"2_orders_down.sql": "",
+			},
+			expectedError: errors.New("migration 2_orders did not rollback cleanly: table orders was not dropped"),
+		},
+		{
+			name: "Test if it errors on migration that does not drop its column",
+			files: map[string]string{
+				"1_users_up.sql":       "CREATE TABLE users (id integer, name text);",
+				"1_users_down.sql":     "DROP TABLE users;",
+				"2_users_age_up.sql":   "ALTER TABLE users ADD COLUMN age integer;",
+				"2_users_age_down.sql": "SELECT 1;",
+			},
+			expectedError: errors.New("migration 2_users_age did not rollback cleanly: table users changed from (column id integer nullable=true, column name text nullable=true) to (column age integer nullable=true, column id integer nullable=true, column name text nullable=true)"),
+		},
+		{
+			name:  "Test if it refuses a database that already has tables",
+			setup: "CREATE TABLE users (id integer); CREATE TABLE migrations (id text);",
+			files: map[string]string{
+				"1_users_up.sql":   "CREATE TABLE users (id integer, name text);",
+				"1_users_down.sql": "DROP TABLE users;",
+			},
+			expectedError: errors.New("can not verify migrations on a database that already has tables: migrations, users"),
+		},
+	}
+	for i, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := tempDir(t)
+			defer func() {
+				_ = os.RemoveAll(dir)
+			}()
+			for fileName, content := range tc.files {
+				writeMigration(t, dir, fileName, content)
+			}
+			dsn := fmt.Sprintf("file:verify_test_%v?mode=memory&cache=shared", i)
+			if tc.setup != "" {
+				// the in memory database lives as long as this connection is open
+				db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+				if err != nil {
+					t.Fatalf("test error: %v", err)
+				}
+				sqlDB, err := db.DB()
+				if err != nil {
+					t.Fatalf("test error: %v", err)
+				}
+				defer sqlDB.Close()
+				err = db.Exec(tc.setup).Error
+				if err != nil {
+					t.Fatalf("test error: %v", err)
+				}
+			}
+			err := migrationhandler.VerifyRollback(migrationhandler.DBConfig{
+				Dialector:            sqlite.Open(dsn),
+				MigrationsFolderPath: "./" + dir,
+			})
+			if err != nil || tc.expectedError != nil {
+				if err == nil || tc.expectedError == nil || err.Error() != tc.expectedError.Error() {
+					t.Errorf("expected: %+v, got: %+v", tc.expectedError, err)
+				}
+			}
+		})
+	}
+}